@@ -0,0 +1,183 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+	"github.com/longhorn/longhorn-preflight/pkg/kube"
+)
+
+const (
+	FlagConfigMapFile = "configmap-file"
+	FlagMaxAge        = "max-age"
+	FlagNodeSelector  = "node-selector"
+	FlagExpectedNodes = "expected-nodes"
+)
+
+func PreflightAggregateCmd() cli.Command {
+	return cli.Command{
+		Name: "aggregate",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:   FlagResultsConfigMap,
+				EnvVar: "RESULTS_CONFIGMAP",
+				Usage:  "ConfigMap the nodes wrote their results to with check --results-configmap, as name or namespace/name, read in-cluster",
+			},
+			cli.StringFlag{
+				Name:  FlagConfigMapFile,
+				Usage: "Read the ConfigMap from this JSON file instead, e.g. written with kubectl get configmap -o json",
+			},
+			cli.DurationFlag{
+				Name:  FlagMaxAge,
+				Usage: "Regard the nodes whose results are older than this duration as stale and not ready, e.g. 1h, or never if 0",
+			},
+			cli.StringFlag{
+				Name:  FlagNodeSelector,
+				Usage: "Label selector of the nodes expected to write their results, listed in-cluster, which are not ready without results, e.g. node.longhorn.io/create-default-disk=true",
+			},
+			cli.IntFlag{
+				Name:  FlagExpectedNodes,
+				Usage: "Number of the nodes expected to write their results with --configmap-file, of which the nodes without results are not ready",
+			},
+			cli.StringFlag{
+				Name:  FlagOutput,
+				Value: checker.OutputText,
+				Usage: "Output format of the cluster readiness: text or json",
+			},
+		},
+		Usage: "Summarize the readiness of the cluster from the results the nodes wrote to a ConfigMap",
+		Action: func(c *cli.Context) {
+			if err := aggregate(c); err != nil {
+				logrus.WithError(err).Fatalf("Failed to run command")
+			}
+		},
+	}
+}
+
+func aggregate(c *cli.Context) error {
+	configMap, err := loadResultsConfigMap(c)
+	if err != nil {
+		return err
+	}
+
+	// In-cluster, the nodes without results are found by listing the nodes
+	var expected []string
+	if c.String(FlagConfigMapFile) == "" {
+		client, err := kube.NewInClusterClient()
+		if err != nil {
+			return err
+		}
+		if expected, err = client.ListNodeNames(c.String(FlagNodeSelector)); err != nil {
+			return fmt.Errorf("failed to list the nodes: %v", err)
+		}
+	}
+
+	nodes := []string{}
+	for node := range configMap.Data {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	reports := []*checker.NodeReport{}
+	for _, node := range nodes {
+		report := &checker.NodeReport{}
+		if err := json.Unmarshal([]byte(configMap.Data[node]), report); err != nil || report.Report == nil || report.Summary == nil {
+			logrus.WithError(err).Warnf("Skipped the results of node %v since they cannot be parsed", node)
+			continue
+		}
+		report.Node = node
+		reports = append(reports, report)
+	}
+
+	cluster := checker.AggregateReports(reports, expected, c.Duration(FlagMaxAge), time.Now())
+	// The nodes without results cannot be named when reading the file
+	if count := c.Int(FlagExpectedNodes); count > cluster.Total {
+		cluster.Missing += count - cluster.Total
+		cluster.Total = count
+	}
+	if cluster.Total == 0 {
+		return fmt.Errorf("no results of any node in ConfigMap %v/%v", configMap.Metadata.Namespace, configMap.Metadata.Name)
+	}
+	if err := checker.PrintClusterReport(os.Stdout, c.String(FlagOutput), cluster); err != nil {
+		return err
+	}
+	if cluster.Ready < cluster.Total {
+		return fmt.Errorf("%d of %d node(s) not ready", cluster.Total-cluster.Ready, cluster.Total)
+	}
+	return nil
+}
+
+// loadResultsConfigMap reads the ConfigMap of the results from the file or
+// in-cluster
+func loadResultsConfigMap(c *cli.Context) (*kube.ConfigMap, error) {
+	if path := c.String(FlagConfigMapFile); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		configMap := &kube.ConfigMap{}
+		if err := json.Unmarshal(content, configMap); err != nil {
+			return nil, fmt.Errorf("failed to parse ConfigMap %v: %v", path, err)
+		}
+		return configMap, nil
+	}
+
+	value := c.String(FlagResultsConfigMap)
+	if value == "" {
+		return nil, fmt.Errorf("either --%v or --%v is required", FlagResultsConfigMap, FlagConfigMapFile)
+	}
+	namespace, name, err := parseResultsConfigMap(value)
+	if err != nil {
+		return nil, err
+	}
+	client, err := kube.NewInClusterClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetConfigMap(namespace, name)
+}
+
+// parseResultsConfigMap returns the namespace and the name of the ConfigMap
+// given as name or namespace/name, defaulting to the namespace of the pod
+func parseResultsConfigMap(value string) (string, string, error) {
+	if namespace, name, ok := strings.Cut(value, "/"); ok {
+		return namespace, name, nil
+	}
+	namespace, err := kube.GetNamespace()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get the namespace of ConfigMap %v, which requires running in a pod or giving it as namespace/name: %v", value, err)
+	}
+	return namespace, value, nil
+}
+
+// publishResults writes the report to the key of the node in the ConfigMap of
+// the results
+func publishResults(options *checker.Options, report *checker.Report) error {
+	if options.NodeName == "" {
+		return fmt.Errorf("cannot publish the results without the node name")
+	}
+	content, err := json.Marshal(&checker.NodeReport{
+		Node:      options.NodeName,
+		Timestamp: time.Now().UTC(),
+		Report:    report,
+	})
+	if err != nil {
+		return err
+	}
+
+	client, err := kube.NewInClusterClient()
+	if err != nil {
+		return err
+	}
+	return client.PatchConfigMapData(options.ResultsConfigMapNamespace, options.ResultsConfigMap, map[string]string{
+		options.NodeName: string(content),
+	})
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+)
+
+// cache is the content of the cache file, which lets the repeated runs with
+// the same configuration reuse the results
+type cache struct {
+	Timestamp  time.Time              `json:"timestamp"`
+	ConfigHash string                 `json:"configHash"`
+	Results    []*checker.CheckResult `json:"results"`
+}
+
+// loadCache returns the cached results, or nil if the cache file does not
+// exist or is invalidated by another configuration or by its age. A maximum
+// age of 0 lets the cache never expire.
+func loadCache(path, configHash string, maxAge time.Duration) []*checker.CheckResult {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logrus.WithError(err).Warnf("Failed to read the cache file %v", path)
+		}
+		return nil
+	}
+
+	c := &cache{}
+	if err := json.Unmarshal(content, c); err != nil {
+		logrus.WithError(err).Warnf("Invalidated the cache file %v since it cannot be parsed", path)
+		return nil
+	}
+	if c.ConfigHash != configHash {
+		logrus.Infof("Invalidated the cache file %v since the configuration changed", path)
+		return nil
+	}
+	if age := time.Since(c.Timestamp); maxAge > 0 && age > maxAge {
+		logrus.Infof("Invalidated the cache file %v since it is %v old, older than %v", path, age.Round(time.Second), maxAge)
+		return nil
+	}
+
+	logrus.Infof("Using the results cached in %v at %v", path, c.Timestamp.Format(time.RFC3339))
+	return c.Results
+}
+
+func saveCache(path, configHash string, results []*checker.CheckResult) error {
+	content, err := json.MarshalIndent(&cache{
+		Timestamp:  time.Now().UTC(),
+		ConfigHash: configHash,
+		Results:    results,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -1,24 +1,327 @@
 package app
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+	"github.com/longhorn/longhorn-preflight/pkg/webhook"
 )
 
-func PreflightCheckCmd() cli.Command {
+func PreflightCheckCmd(packageManager types.PackageManager) cli.Command {
 	return cli.Command{
 		Name:  "check",
-		Flags: []cli.Flag{},
+		Flags: append(checkerFlags(), outputFlags()...),
 		Usage: "Check environment",
 		Action: func(c *cli.Context) {
-			if err := check(c); err != nil {
+			rebootRequired, err := check(c, packageManager)
+			if err != nil {
 				logrus.WithError(err).Fatalf("Failed to run command")
 			}
-
+			if rebootRequired {
+				os.Exit(ExitCodeRebootRequired)
+			}
 		},
 	}
 }
 
-func check(c *cli.Context) error {
+// check runs the checks and reports their results, and returns whether the
+// node has to be rebooted for the prerequisites fixed with --fix
+func check(c *cli.Context, packageManager types.PackageManager) (_ bool, runErr error) {
+	options, err := newCheckerOptions(c, packageManager)
+	if err != nil {
+		return false, err
+	}
+
+	if path := c.String(FlagFacts); path != "" {
+		facts, err := checker.LoadFacts(path)
+		if err != nil {
+			return false, err
+		}
+		options.Host = facts
+
+		if options.PackageManager == types.PackageManagerUnknown {
+			if options.PackageManager, err = checker.DetectPackageManager(facts); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if c.Bool(FlagDumpEnv) {
+		return false, printEnvironment(os.Stdout, c.String(FlagOutput), newEnvironment(c, options))
+	}
+
+	minSeverity, err := checker.ParseMinSeverity(c.String(FlagMinSeverity))
+	if err != nil {
+		return false, err
+	}
+
+	if err := checkOutputFilesWritable(c); err != nil {
+		return false, err
+	}
+
+	fix := c.Bool(FlagFix)
+	if fix {
+		if _, ok := options.Host.(*checker.FactsHost); ok {
+			return false, fmt.Errorf("cannot fix the checks evaluated against the facts")
+		}
+		// The checkers not installing packages are fixed without the installer
+		if options.Installer, err = installer.NewInstaller(options.PackageManager); err != nil {
+			logrus.WithError(err).Warn("Failed to create the installer, the packages cannot be installed")
+		}
+	}
+
+	var sink *webhook.Sink
+	if url := c.String(FlagWebhookURL); url != "" {
+		sink = webhook.NewSink(url, getNodeName(), getConfigHash(c))
+	}
+	var summary *checker.Summary
+	if sink != nil && c.Bool(FlagWebhookLifecycle) {
+		if err := sink.PostRunStarted(); err != nil {
+			logrus.WithError(err).Warn("Failed to post the run-started event")
+		}
+		// The run-finished event is posted however the run ends, with the
+		// error if it aborts
+		defer func() {
+			if err := sink.PostRunFinished(summary, runErr); err != nil {
+				logrus.WithError(err).Warn("Failed to post the run-finished event")
+			}
+		}()
+	}
+
+	var checkers []checker.Checker
+	var results []*checker.CheckResult
+	if name := c.String(FlagRunCheck); name != "" {
+		checkers, results, err = runSingleCheck(options, name)
+	} else if fix {
+		checkers, results, err = runChecks(options)
+	} else {
+		checkers, results, err = runChecksWithCache(c, options)
+	}
+	if err != nil {
+		return false, err
+	}
+	// The reboots pending before fixing, e.g. for a newer installed kernel,
+	// are reported by the checks but not caused by the fix
+	rebootPending := map[string]bool{}
+	if fix {
+		rebootPending = checker.GetRebootRequiredChecks(checkers, results)
+		if checkers, results, err = fixChecks(c, options, checkers); err != nil {
+			return false, err
+		}
+	}
+
+	summary = checker.Summarize(results)
+	if err := writeReport(c, options, checkers, results, minSeverity); err != nil {
+		return false, err
+	}
+	if !fix {
+		logPendingFixes(checkers, results)
+	}
+
+	if sink != nil {
+		if err := sink.PostResults(results, summary); err != nil {
+			return false, err
+		}
+	}
+	published := false
+	if options.ResultsConfigMap != "" {
+		if options.IsPreconditionFailed(checker.PreconditionResultsConfigMapRBAC) {
+			logrus.Warn("Skipped writing the results to the ConfigMap since the service account cannot create or patch it")
+		} else {
+			// Only the results not passing are published to keep the
+			// ConfigMap of all the nodes small
+			report := &checker.Report{
+				LonghornVersion: options.Requirements.Version,
+				Results:         checker.FilterResults(results, checker.StatusWarn),
+				Summary:         summary,
+			}
+			if err := publishResults(options, report); err != nil {
+				return false, err
+			}
+			logrus.Infof("Wrote the results to ConfigMap %v/%v", options.ResultsConfigMapNamespace, options.ResultsConfigMap)
+			published = true
+		}
+	}
+	if options.WriteNodeStatus {
+		if options.IsPreconditionFailed(checker.PreconditionNodeStatusRBAC) {
+			logrus.Warn("Skipped writing the node status since the service account cannot patch the node")
+		} else if err := writeNodeStatus(options.NodeName, summary); err != nil {
+			return false, err
+		}
+	}
+	if options.WriteNodeCondition {
+		if options.IsPreconditionFailed(checker.PreconditionNodeStatusRBAC) {
+			logrus.Warn("Skipped writing the node condition since the service account cannot patch the node status")
+		} else if err := writeNodeCondition(options.NodeName, summary); err != nil {
+			return false, err
+		}
+	}
+	// The checks fixed with --fix may only pass after rebooting the node
+	if fix && isRebootRequiredByFix(options, checkers, results, rebootPending) {
+		logrus.Warn("Node requires a reboot for the fixed prerequisites to take effect")
+		return true, nil
+	}
+
+	if summary.Failed > 0 {
+		// The aggregation reports the failures of the published results
+		if published {
+			logrus.Warnf("%d check(s) failed", summary.Failed)
+			return false, nil
+		}
+		return false, fmt.Errorf("%d check(s) failed", summary.Failed)
+	}
+	return false, nil
+}
+
+// isRebootRequiredByFix checks whether the fix installed packages in a new
+// snapshot, or made any check require a reboot which did not before fixing
+func isRebootRequiredByFix(options *checker.Options, checkers []checker.Checker, results []*checker.CheckResult, rebootPending map[string]bool) bool {
+	if options.Installer != nil && options.Installer.IsRebootRequired() {
+		return true
+	}
+	for name := range checker.GetRebootRequiredChecks(checkers, results) {
+		if !rebootPending[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// runChecks runs the preconditions and the enabled checkers, and returns the
+// checkers with the results of both
+func runChecks(options *checker.Options) ([]checker.Checker, []*checker.CheckResult, error) {
+	preconditionResults, err := checker.CheckPreconditions(checker.NewCheckPreconditions(options), options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checkers, err := checker.NewCheckers(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return checkers, append(preconditionResults, checker.RunRepeatedly(checkers, options, options.StabilityRuns)...), nil
+}
+
+// runSingleCheck runs the preconditions and only the named check, and returns
+// the result of the check alone. The check may be one of the preconditions.
+// The checks it depends on are not run, so they are not regarded as failed.
+func runSingleCheck(options *checker.Options, name string) ([]checker.Checker, []*checker.CheckResult, error) {
+	preconditions := checker.NewCheckPreconditions(options)
+	preconditionResults, err := checker.CheckPreconditions(preconditions, options)
+	for i, result := range preconditionResults {
+		if result.Name == name {
+			return preconditions[i : i+1], []*checker.CheckResult{result}, nil
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checkers, err := checker.NewCheckers(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	selected, err := checker.SelectCheckers(checkers, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return selected, checker.RunRepeatedly(selected, options, options.StabilityRuns), nil
+}
+
+// runChecksWithCache returns the results cached by a previous run if the
+// cache file is valid, or runs the checks and caches their results
+func runChecksWithCache(c *cli.Context, options *checker.Options) ([]checker.Checker, []*checker.CheckResult, error) {
+	path := c.String(FlagCacheFile)
+	if path == "" {
+		return runChecks(options)
+	}
+
+	configHash := getConfigHash(c)
+	if results := loadCache(path, configHash, c.Duration(FlagCacheMaxAge)); results != nil {
+		preconditions := map[string]bool{}
+		for _, c := range checker.NewCheckPreconditions(options) {
+			preconditions[c.Name()] = true
+		}
+		for _, result := range results {
+			switch {
+			case preconditions[result.Name] && (result.Status == checker.StatusFail || result.Status == checker.StatusUnknown):
+				options.FailedPreconditions[result.Name] = result.Message
+			case result.Status == checker.StatusFail:
+				options.FailedChecks[result.Name] = result.Message
+			}
+		}
+		checkers, err := checker.NewCheckers(options)
+		if err != nil {
+			return nil, nil, err
+		}
+		return checkers, results, nil
+	}
+
+	checkers, results, err := runChecks(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := saveCache(path, configHash, results); err != nil {
+		logrus.WithError(err).Warnf("Failed to write the cache file %v", path)
+	}
+	return checkers, results, nil
+}
+
+// writeReport writes the report of the results in the output format to the
+// output file or stdout
+func writeReport(c *cli.Context, options *checker.Options, checkers []checker.Checker, results []*checker.CheckResult, minSeverity checker.Status) error {
+	checker.AnnotateResults(checkers, results)
+	summary := checker.Summarize(results)
+	report := &checker.Report{
+		LonghornVersion: options.Requirements.Version,
+		Results:         checker.FilterResults(checker.GroupResults(results), minSeverity),
+		Summary:         summary,
+	}
+	if c.Bool(FlagScore) {
+		report.Score = checker.ComputeScore(results, &options.Config.Scoring)
+	}
+
+	output, err := openOutput(c.String(FlagOutputFile))
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	if c.String(FlagOutput) == checker.OutputPlan {
+		err = checker.PrintPlan(output, checker.NewPlan(checkers, results, options))
+	} else {
+		err = checker.PrintReport(output, c.String(FlagOutput), report)
+	}
+	if err != nil {
+		return err
+	}
+	if c.Bool(FlagEmitTrailer) {
+		checker.PrintTrailer(os.Stdout, summary)
+	}
+
+	if path := c.String(FlagResultFile); path != "" {
+		// The result file is not filtered by the minimum severity
+		report.Results = results
+		if err := writeResultFile(path, report); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+func writeResultFile(path string, report *checker.Report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return checker.PrintReport(file, checker.OutputJSON, report)
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+)
+
+func PreflightDiffCmd() cli.Command {
+	return cli.Command{
+		Name:      "diff",
+		ArgsUsage: "<result-a.json> <result-b.json>",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  FlagOutput,
+				Value: checker.OutputText,
+				Usage: "Output format of the differences: text or json",
+			},
+		},
+		Usage: "Compare the JSON results of two nodes, e.g. written with --result-file",
+		Action: func(c *cli.Context) {
+			if err := diff(c); err != nil {
+				logrus.WithError(err).Fatalf("Failed to run command")
+			}
+		},
+	}
+}
+
+func diff(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("expected two result files, got %d", c.NArg())
+	}
+	pathA, pathB := c.Args().Get(0), c.Args().Get(1)
+
+	a, err := checker.LoadReport(pathA)
+	if err != nil {
+		return err
+	}
+	b, err := checker.LoadReport(pathB)
+	if err != nil {
+		return err
+	}
+
+	return checker.PrintDiffs(os.Stdout, c.String(FlagOutput), pathA, pathB, checker.DiffResults(a.Results, b.Results))
+}
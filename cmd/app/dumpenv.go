@@ -0,0 +1,86 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+
+	"github.com/urfave/cli"
+
+	lhns "github.com/longhorn/go-common-libs/namespace"
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+	"github.com/longhorn/longhorn-preflight/pkg/utils"
+)
+
+// environment is the view of the node before running the checks
+type environment struct {
+	Distro         string            `json:"distro"`
+	Kernel         string            `json:"kernel"`
+	Arch           string            `json:"arch"`
+	PackageManager string            `json:"packageManager"`
+	HostRoot       string            `json:"hostRoot"`
+	ProcDirectory  string            `json:"procDirectory"`
+	ProcessName    string            `json:"processName"`
+	Flags          map[string]string `json:"flags"`
+}
+
+func newEnvironment(c *cli.Context, options *checker.Options) *environment {
+	env := &environment{
+		Arch:           runtime.GOARCH,
+		PackageManager: string(options.PackageManager),
+		HostRoot:       types.HostRootDirectory,
+		ProcDirectory:  lhtypes.HostProcDirectory,
+		ProcessName:    options.ProcName,
+		Flags:          map[string]string{},
+	}
+
+	if distro, err := utils.GetOSRelease(); err != nil {
+		env.Distro = fmt.Sprintf("unknown (%v)", err)
+	} else {
+		env.Distro = distro
+	}
+
+	if kernel, err := lhns.GetKernelRelease(); err != nil {
+		env.Kernel = fmt.Sprintf("unknown (%v)", err)
+	} else {
+		env.Kernel = kernel
+	}
+
+	for _, name := range c.FlagNames() {
+		env.Flags[name] = c.String(name)
+	}
+
+	return env
+}
+
+func printEnvironment(w io.Writer, output string, env *environment) error {
+	if output == checker.OutputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(env)
+	}
+
+	fmt.Fprintf(w, "Distro: %v\n", env.Distro)
+	fmt.Fprintf(w, "Kernel: %v\n", env.Kernel)
+	fmt.Fprintf(w, "Arch: %v\n", env.Arch)
+	fmt.Fprintf(w, "Package manager: %v\n", env.PackageManager)
+	fmt.Fprintf(w, "Host root: %v\n", env.HostRoot)
+	fmt.Fprintf(w, "Proc directory: %v\n", env.ProcDirectory)
+	fmt.Fprintf(w, "Process name: %q\n", env.ProcessName)
+	fmt.Fprintln(w, "Flags:")
+
+	names := []string{}
+	for name := range env.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  --%v=%v\n", name, env.Flags[name])
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+)
+
+// fixChecks installs the prerequisites of the checks not passing, and returns
+// the checkers with the results of running the checks again
+func fixChecks(c *cli.Context, options *checker.Options, checkers []checker.Checker) ([]checker.Checker, []*checker.CheckResult, error) {
+	if options.IsPreconditionFailed(checker.PreconditionHostNamespace) {
+		return nil, nil, fmt.Errorf("cannot fix anything without entering the host namespaces")
+	}
+
+	// The preconditions of the installation, e.g. the package database is not
+	// locked, are not run by the checks
+	logrus.Info("Checking preconditions of fixing")
+	if _, err := checker.CheckPreconditions(checker.NewPreconditions(options), options); err != nil {
+		return nil, nil, err
+	}
+	checker.CheckStepPreconditions(checker.NewStepPreconditions(options), options)
+
+	// The checks are fixed in order, so the checks depending on a failed one
+	// are fixed after it
+	options.FailedChecks = map[string]string{}
+	logrus.Info("Fixing the prerequisites of the checks not passing")
+	checker.Install(checkers, options)
+
+	logrus.Info("Running the checks again after fixing")
+	options.FailedPreconditions = map[string]string{}
+	if name := c.String(FlagRunCheck); name != "" {
+		return runSingleCheck(options, name)
+	}
+	checkers, results, err := runChecks(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	if path := c.String(FlagCacheFile); path != "" {
+		if err := saveCache(path, getConfigHash(c), results); err != nil {
+			logrus.WithError(err).Warnf("Failed to write the cache file %v", path)
+		}
+	}
+	return checkers, results, nil
+}
+
+// logPendingFixes logs the checks not passing which would be fixed by running
+// with --fix, in check-only mode
+func logPendingFixes(checkers []checker.Checker, results []*checker.CheckResult) {
+	installers := map[string]bool{}
+	for _, c := range checkers {
+		if _, ok := c.(checker.Installer); ok {
+			installers[c.Name()] = true
+		}
+	}
+
+	for _, result := range results {
+		if result.Status != checker.StatusWarn && result.Status != checker.StatusFail {
+			continue
+		}
+		if !installers[result.Name] {
+			continue
+		}
+		change := result.Remediation
+		if change == "" {
+			change = "install or configure " + result.Name
+		}
+		logrus.Infof("Check-only mode, --fix would change: %v (%v)", change, result.ID())
+	}
+}
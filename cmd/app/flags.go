@@ -0,0 +1,520 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const (
+	FlagLonghornVersion         = "longhorn-version"
+	FlagEnableSPDK              = "enable-spdk"
+	FlagDriverOverride          = "driver-override"
+	FlagPersistIOMMU            = "persist-iommu"
+	FlagNVMeTransport           = "nvme-transport"
+	FlagHugePages               = "hugepages"
+	FlagPersistHugePages        = "persist-hugepages"
+	FlagSetHugePages            = "set-hugepages"
+	FlagRequireAVX2             = "require-avx2"
+	FlagEnableEncryption        = "enable-encryption"
+	FlagConfig                  = "config"
+	FlagExceptionsFile          = "exceptions-file"
+	FlagDevPath                 = "dev-path"
+	FlagMinShmSize              = "min-shm-size"
+	FlagMinPidMax               = "min-pid-max"
+	FlagMinInotifyWatches       = "min-inotify-watches"
+	FlagMinInotifyInstances     = "min-inotify-instances"
+	FlagBestEffort              = "best-effort"
+	FlagDataDevice              = "data-device"
+	FlagDiskPath                = "disk-path"
+	FlagKubeletRootDir          = "kubelet-root-dir"
+	FlagSetIOScheduler          = "set-io-scheduler"
+	FlagRebuildInitramfs        = "rebuild-initramfs"
+	FlagPersistModules          = "persist-modules"
+	FlagInstallKernel           = "install-kernel"
+	FlagCleanupStaleDM          = "cleanup-stale-dm"
+	FlagCheckJQ                 = "check-jq"
+	FlagCheckSG3                = "check-sg3"
+	FlagCheckWipefs             = "check-wipefs"
+	FlagCheckTun                = "check-tun"
+	FlagCheckAttrTools          = "check-attr-tools"
+	FlagCheckFuse               = "check-fuse"
+	FlagCheckIrqbalance         = "check-irqbalance"
+	FlagCheckRawSocket          = "check-raw-socket"
+	FlagCheckHostsFile          = "check-hosts-file"
+	FlagCheckNUMA               = "check-numa"
+	FlagCheckHWRNG              = "check-hwrng"
+	FlagCheckKubeletDelegate    = "check-kubelet-delegate"
+	FlagCheckFileCapabilities   = "check-file-capabilities"
+	FlagCheckTuned              = "check-tuned"
+	FlagCheckRTC                = "check-rtc"
+	FlagCheckNBD                = "check-nbd"
+	FlagNbdsMax                 = "nbds-max"
+	FlagCheckHTTPTools          = "check-http-tools"
+	FlagBackupTargetURL         = "backup-target-url"
+	FlagCheckBonding            = "check-bonding"
+	FlagStorageInterface        = "storage-interface"
+	FlagCheckFSMaint            = "check-fs-maint"
+	FlagRequiredHostname        = "required-hostname"
+	FlagCheckPluginDir          = "check-plugin-dir"
+	FlagModuleAllowlist         = "module-allowlist"
+	FlagModuleAllowlistSeverity = "module-allowlist-severity"
+
+	FlagOutput             = "output"
+	FlagReportFormat       = "report-format"
+	FlagOutputFile         = "output-file"
+	FlagResultFile         = "result-file"
+	FlagMinSeverity        = "min-severity"
+	FlagDumpEnv            = "dump-env"
+	FlagFacts              = "facts"
+	FlagEmitTrailer        = "emit-trailer"
+	FlagScore              = "score"
+	FlagWebhookURL         = "webhook-url"
+	FlagWriteNodeStatus    = "write-node-status"
+	FlagWriteNodeCondition = "write-node-condition"
+	FlagResultsConfigMap   = "results-configmap"
+	FlagWebhookLifecycle   = "webhook-lifecycle"
+	FlagCacheFile          = "cache-file"
+	FlagCacheMaxAge        = "cache-max-age"
+	FlagRunCheck           = "run-check"
+	FlagStabilityRuns      = "stability-runs"
+	FlagFix                = "fix"
+)
+
+func checkerFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:   FlagLonghornVersion,
+			EnvVar: "LONGHORN_VERSION",
+			Usage:  "Longhorn release validated for, e.g. v1.6.2, which selects its prerequisites. Supports v1.4 and later, and defaults to the latest release",
+		},
+		cli.BoolFlag{
+			Name:   FlagEnableSPDK,
+			EnvVar: "ENABLE_SPDK",
+			Usage:  "Enable the checks and installation for the v2 data engine. The installation loads its modules, reserves the HugePages and binds the NVMe devices with the driver",
+		},
+		cli.StringFlag{
+			Name:   FlagDriverOverride,
+			EnvVar: "DRIVER_OVERRIDE",
+			Value:  "uio_pci_generic",
+			Usage:  "Driver used to bind the NVMe devices for the v2 data engine, e.g. uio_pci_generic or vfio-pci",
+		},
+		cli.BoolFlag{
+			Name:  FlagPersistIOMMU,
+			Usage: "Enable the IOMMU required by vfio-pci in the GRUB config in install mode, which requires a reboot",
+		},
+		cli.StringFlag{
+			Name:  FlagNVMeTransport,
+			Value: checker.NVMeTransportTCP,
+			Usage: "NVMe-oF transport of the v2 data engine: tcp or rdma, which checks the RDMA modules and loads them in install mode",
+		},
+		cli.IntFlag{
+			Name:  FlagHugePages,
+			Value: 1024,
+			Usage: "Number of 2MiB HugePages for the v2 data engine",
+		},
+		cli.BoolFlag{
+			Name:  FlagPersistHugePages,
+			Usage: "Persist the HugePages for the v2 data engine in the GRUB config in install mode, which requires a reboot",
+		},
+		cli.BoolFlag{
+			Name:  FlagSetHugePages,
+			Usage: "Allocate the HugePages for the v2 data engine with vm.nr_hugepages in install mode, which is persisted in the sysctl config",
+		},
+		cli.BoolFlag{
+			Name:  FlagRequireAVX2,
+			Usage: "Require the CPU to support AVX2 besides SSE4.2 for the v2 data engine, e.g. when SPDK is built for it",
+		},
+		cli.BoolFlag{
+			Name:   FlagEnableEncryption,
+			EnvVar: "ENABLE_ENCRYPTION",
+			Usage:  "Enable the checks and installation for the encrypted volumes",
+		},
+		cli.StringFlag{
+			Name:   FlagExceptionsFile,
+			EnvVar: "EXCEPTIONS_FILE",
+			Usage:  "File listing the checks skipped on this node, one \"<check-name>: <reason>\" per line",
+		},
+		cli.StringFlag{
+			Name:   FlagConfig,
+			EnvVar: "CONFIG",
+			Usage:  "JSON configuration file of the checks",
+		},
+		cli.BoolFlag{
+			Name:  FlagBestEffort,
+			Usage: "Continue when preconditions fail, and report the checks depending on them as unknown",
+		},
+		cli.StringFlag{
+			Name:  FlagDevPath,
+			Value: "/dev",
+			Usage: "Path where the host /dev is mounted in the container",
+		},
+		cli.IntFlag{
+			Name:  FlagMinShmSize,
+			Usage: "Minimum size of /dev/shm in MiB, defaults to 64 or 256 for the v2 data engine",
+		},
+		cli.IntFlag{
+			Name:  FlagMinPidMax,
+			Value: 65536,
+			Usage: "Minimum kernel.pid_max, which is raised to this value and persisted in install mode",
+		},
+		cli.IntFlag{
+			Name:  FlagMinInotifyWatches,
+			Value: 524288,
+			Usage: "Minimum fs.inotify.max_user_watches, which is raised to this value and persisted in install mode",
+		},
+		cli.IntFlag{
+			Name:  FlagMinInotifyInstances,
+			Value: 512,
+			Usage: "Minimum fs.inotify.max_user_instances, which is raised to this value and persisted in install mode",
+		},
+		cli.StringSliceFlag{
+			Name:   FlagDiskPath,
+			EnvVar: "DISK_PATH",
+			Usage:  "Path of the Longhorn data on the node, repeated for several disks (default: /var/lib/longhorn)",
+		},
+		cli.StringFlag{
+			Name:   FlagKubeletRootDir,
+			EnvVar: "KUBELET_ROOT_DIR",
+			Value:  "/var/lib/kubelet",
+			Usage:  "Root directory of the kubelet, whose plugins and pods directories must be on shared mounts",
+		},
+		cli.StringFlag{
+			Name:   FlagDataDevice,
+			EnvVar: "DATA_DEVICE",
+			Usage:  "Block device storing the Longhorn data, e.g. /dev/nvme0n1",
+		},
+		cli.BoolFlag{
+			Name:  FlagSetIOScheduler,
+			Usage: "Set the recommended I/O scheduler of the data device with a udev rule in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagRebuildInitramfs,
+			Usage: "Rebuild the initramfs at the end of the installation to include the persisted modules, after checking /boot has space for it",
+		},
+		cli.BoolFlag{
+			Name:  FlagPersistModules,
+			Usage: "Check the required modules are loaded on boot by /etc/modules-load.d/longhorn.conf, or /etc/modules without systemd, and write it in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCleanupStaleDM,
+			Usage: "Remove the device-mapper devices of the Longhorn volumes left behind without the Longhorn block devices in install mode, unless they are open",
+		},
+		cli.StringFlag{
+			Name:  FlagInstallKernel,
+			Usage: "Install the kernel package of the version, e.g. 6.8.0-45-generic on Ubuntu, in install mode. The node must be rebooted into the new kernel afterwards",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckJQ,
+			Usage: "Check jq required by the user scripts, and install it in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckSG3,
+			Usage: "Check the sg3_utils tools for SCSI management, and install them in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckWipefs,
+			Usage: "Check wipefs for disk preparation, and install it in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckTun,
+			Usage: "Check the tun module for network storage over tunnels, and load it in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckAttrTools,
+			Usage: "Check chattr and lsattr for handling the immutable flag of the files, and install them in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckFileCapabilities,
+			Usage: "Check setcap and getcap, and the filesystem support of the file capabilities, and install the tools in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckNBD,
+			Usage: "Check the nbd module and /dev/nbd0 for the NBD-based features, and load the module in install mode",
+		},
+		cli.IntFlag{
+			Name:  FlagNbdsMax,
+			Value: 16,
+			Usage: "Number of the NBD devices created when loading the nbd module with --check-nbd in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckHTTPTools,
+			Usage: "Check curl or wget for reaching the backup targets over HTTP, and install curl in install mode",
+		},
+		cli.StringFlag{
+			Name:   FlagBackupTargetURL,
+			EnvVar: "BACKUP_TARGET_URL",
+			Usage:  "HTTP URL of the backup target probed for reachability with --check-http-tools, left empty on the air-gapped nodes",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckFSMaint,
+			Usage: "Check the defragmentation and repair tools of the filesystems of the data paths, and install them in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckFuse,
+			Usage: "Check the fuse module and /dev/fuse for the FUSE-based features, and load the module in install mode",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckIrqbalance,
+			Usage: "Check irqbalance distributing the IRQs on the nodes with many CPUs",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckNUMA,
+			Usage: "Check HugePages are reserved on the NUMA node of the data device for the v2 data engine, and report the NUMA topology",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckHWRNG,
+			Usage: "Check a hardware RNG or rngd provides the entropy for creating many encrypted volumes, with --enable-encryption",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckKubeletDelegate,
+			Usage: "Check the systemd unit of the kubelet sets Delegate=yes for the cgroup accounting of the pods",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckTuned,
+			Usage: "Check the active tuned profile is tuned for throughput or latency rather than for saving power",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckRTC,
+			Usage: "Check the real-time clock is close to the system clock, so the node boots with a sane clock before NTP syncs it",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckBonding,
+			Usage: "Check the bonding mode of the storage interface, or of every bond if none is given, is compatible with iSCSI multipath",
+		},
+		cli.StringFlag{
+			Name:   FlagStorageInterface,
+			EnvVar: "STORAGE_INTERFACE",
+			Usage:  "Network interface of the storage network, e.g. bond0",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckRawSocket,
+			Usage: "Check a raw AF_PACKET socket can be created in the host network namespace for the networking diagnostics",
+		},
+		cli.BoolFlag{
+			Name:  FlagCheckHostsFile,
+			Usage: "Check /etc/hosts resolves the hostnames given by --required-hostname",
+		},
+		cli.StringSliceFlag{
+			Name:  FlagRequiredHostname,
+			Usage: "Hostname /etc/hosts must resolve, e.g. of the registry in an air-gapped cluster. Can be repeated",
+		},
+		cli.StringFlag{
+			Name:  FlagModuleAllowlist,
+			Usage: "File listing the approved kernel modules, one per line, and report the loaded modules not on it",
+		},
+		cli.StringFlag{
+			Name:  FlagModuleAllowlistSeverity,
+			Value: "warn",
+			Usage: "Status of the loaded modules not on the allowlist: warn or fail",
+		},
+		cli.StringFlag{
+			Name:  FlagCheckPluginDir,
+			Usage: "Directory of the executables run as external checks, each printing a JSON result {\"status\": ..., \"message\": ...} on stdout within 30 seconds",
+		},
+	}
+}
+
+// reportFlags returns the flags rendering the check results
+func reportFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  FlagOutput + ", " + FlagReportFormat,
+			Value: checker.OutputText,
+			Usage: "Output format of the check results: text, json or yaml, or plan for the JSON remediation plan of the failing checks. The json and yaml reports include the severity and the remediation hint of each check",
+		},
+		cli.StringFlag{
+			Name:  FlagOutputFile,
+			Usage: "Write the check results to this file instead of stdout",
+		},
+		cli.StringFlag{
+			Name:  FlagResultFile,
+			Usage: "Also write all the check results as JSON to this file, regardless of the output format",
+		},
+		cli.StringFlag{
+			Name:  FlagMinSeverity,
+			Usage: "Only output the check results at least as severe as this: warn or fail. The summary and the exit code still cover all results",
+		},
+		cli.BoolFlag{
+			Name:  FlagEmitTrailer,
+			Usage: "Print \"PREFLIGHT_RESULT: <PASS|WARN|UNKNOWN|FAIL> (<n> failures, <n> warnings)\" as the last line of the output in any format",
+		},
+		cli.BoolFlag{
+			Name:  FlagScore,
+			Usage: "Grade the node from the weighted check results, with the weights and mandatory checks in the scoring section of the config",
+		},
+	}
+}
+
+func outputFlags() []cli.Flag {
+	return append(reportFlags(),
+		cli.BoolFlag{
+			Name:  FlagDumpEnv,
+			Usage: "Print the detected environment and the effective flags, then exit without running the checks",
+		},
+		cli.StringFlag{
+			Name:  FlagFacts,
+			Usage: "Evaluate the checks against the facts collected separately in this JSON file instead of probing the host",
+		},
+		cli.BoolFlag{
+			Name:  FlagWriteNodeStatus,
+			Usage: "Write the overall result and the summary to the annotations of the node, which requires the service account to patch the nodes",
+		},
+		cli.BoolFlag{
+			Name:  FlagWriteNodeCondition,
+			Usage: "Set the condition " + nodeConditionType + " of the node to True unless any check failed, which requires the service account to patch the nodes/status",
+		},
+		cli.StringFlag{
+			Name:   FlagResultsConfigMap,
+			EnvVar: "RESULTS_CONFIGMAP",
+			Usage:  "Write the report to this ConfigMap, as name in the namespace of the pod or namespace/name, keyed by the node name for the aggregate command. The run then succeeds once the report is written, whatever the results",
+		},
+		cli.StringFlag{
+			Name:   FlagWebhookURL,
+			EnvVar: "WEBHOOK_URL",
+			Usage:  "Post the check results as JSON to this URL",
+		},
+		cli.BoolFlag{
+			Name:  FlagWebhookLifecycle,
+			Usage: "Also post the run-started and run-finished events to the webhook",
+		},
+		cli.StringFlag{
+			Name:  FlagRunCheck,
+			Usage: "Run only the check of this name, or of the name qualified by the target, e.g. disk-path-filesystem:/var/lib/longhorn, and report its result alone",
+		},
+		cli.IntFlag{
+			Name:  FlagStabilityRuns,
+			Value: 1,
+			Usage: "Run each check this many times and mark the checks whose status varied across the runs as flaky",
+		},
+		cli.StringFlag{
+			Name:  FlagCacheFile,
+			Usage: "Reuse the results cached in this file by a previous run with the same flags instead of running the checks, and cache the results of a fresh run",
+		},
+		cli.DurationFlag{
+			Name:  FlagCacheMaxAge,
+			Usage: "Invalidate the cached results older than this duration, e.g. 1h, or never if 0",
+		},
+		cli.BoolFlag{
+			Name:  FlagFix,
+			Usage: "Install or configure the prerequisites of the checks not passing, then report the results of running the checks again. Without it, the checks only report what would be changed",
+		},
+	)
+}
+
+func newCheckerOptions(c *cli.Context, packageManager types.PackageManager) (*checker.Options, error) {
+	options := checker.NewOptions(packageManager)
+	options.NodeName = getNodeName()
+	options.WriteNodeStatus = c.Bool(FlagWriteNodeStatus)
+	options.WriteNodeCondition = c.Bool(FlagWriteNodeCondition)
+	if value := c.String(FlagResultsConfigMap); value != "" {
+		namespace, name, err := parseResultsConfigMap(value)
+		if err != nil {
+			return nil, err
+		}
+		options.ResultsConfigMapNamespace, options.ResultsConfigMap = namespace, name
+	}
+	options.EnableSPDK = c.Bool(FlagEnableSPDK)
+	options.DriverOverride = c.String(FlagDriverOverride)
+	options.PersistIOMMU = c.Bool(FlagPersistIOMMU)
+	options.NVMeTransport = c.String(FlagNVMeTransport)
+	if options.NVMeTransport != checker.NVMeTransportTCP && options.NVMeTransport != checker.NVMeTransportRDMA {
+		return nil, fmt.Errorf("invalid NVMe transport %v, must be one of tcp or rdma", options.NVMeTransport)
+	}
+	options.HugePages = c.Int(FlagHugePages)
+	options.PersistHugePages = c.Bool(FlagPersistHugePages)
+	options.SetHugePages = c.Bool(FlagSetHugePages)
+	options.RequireAVX2 = c.Bool(FlagRequireAVX2)
+	options.EnableEncryption = c.Bool(FlagEnableEncryption)
+	options.BestEffort = c.Bool(FlagBestEffort)
+	options.StabilityRuns = c.Int(FlagStabilityRuns)
+	options.DevPath = c.String(FlagDevPath)
+	options.MinShmSize = c.Int(FlagMinShmSize)
+	options.MinPidMax = c.Int(FlagMinPidMax)
+	options.MinInotifyWatches = c.Int(FlagMinInotifyWatches)
+	options.MinInotifyInstances = c.Int(FlagMinInotifyInstances)
+	options.DataDevice = c.String(FlagDataDevice)
+	if diskPaths := c.StringSlice(FlagDiskPath); len(diskPaths) > 0 {
+		options.DiskPaths = diskPaths
+	}
+	options.KubeletRootDir = c.String(FlagKubeletRootDir)
+	options.SetIOScheduler = c.Bool(FlagSetIOScheduler)
+	options.RebuildInitramfs = c.Bool(FlagRebuildInitramfs)
+	options.PersistModules = c.Bool(FlagPersistModules)
+	options.CleanupStaleDM = c.Bool(FlagCleanupStaleDM)
+	options.CheckJQ = c.Bool(FlagCheckJQ)
+	options.CheckSG3 = c.Bool(FlagCheckSG3)
+	options.CheckWipefs = c.Bool(FlagCheckWipefs)
+	options.CheckTun = c.Bool(FlagCheckTun)
+	options.CheckAttrTools = c.Bool(FlagCheckAttrTools)
+	options.CheckFuse = c.Bool(FlagCheckFuse)
+	options.CheckIrqbalance = c.Bool(FlagCheckIrqbalance)
+	options.CheckRawSocket = c.Bool(FlagCheckRawSocket)
+	options.CheckHostsFile = c.Bool(FlagCheckHostsFile)
+	options.CheckNUMA = c.Bool(FlagCheckNUMA)
+	options.CheckHWRNG = c.Bool(FlagCheckHWRNG)
+	options.CheckKubeletDelegate = c.Bool(FlagCheckKubeletDelegate)
+	options.CheckFileCapabilities = c.Bool(FlagCheckFileCapabilities)
+	options.CheckTuned = c.Bool(FlagCheckTuned)
+	options.CheckRTC = c.Bool(FlagCheckRTC)
+	options.CheckNBD = c.Bool(FlagCheckNBD)
+	options.NbdsMax = c.Int(FlagNbdsMax)
+	options.CheckHTTPTools = c.Bool(FlagCheckHTTPTools)
+	options.BackupTargetURL = c.String(FlagBackupTargetURL)
+	options.CheckBonding = c.Bool(FlagCheckBonding)
+	options.StorageInterface = c.String(FlagStorageInterface)
+	options.CheckFSMaint = c.Bool(FlagCheckFSMaint)
+	options.RequiredHostnames = c.StringSlice(FlagRequiredHostname)
+	if options.CheckHostsFile && len(options.RequiredHostnames) == 0 {
+		return nil, fmt.Errorf("--%v requires at least one --%v", FlagCheckHostsFile, FlagRequiredHostname)
+	}
+	options.PluginDir = c.String(FlagCheckPluginDir)
+
+	requirements, err := checker.GetRequirements(c.String(FlagLonghornVersion))
+	if err != nil {
+		return nil, err
+	}
+	options.Requirements = requirements
+	options.LonghornVersionSet = c.IsSet(FlagLonghornVersion)
+	if options.EnableSPDK && !requirements.V2DataEngine {
+		return nil, fmt.Errorf("longhorn %v does not support the v2 data engine", requirements.Version)
+	}
+	if !c.IsSet(FlagHugePages) && requirements.HugePages > 0 {
+		options.HugePages = requirements.HugePages
+	}
+
+	if path := c.String(FlagConfig); path != "" {
+		config, err := checker.LoadConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		options.Config = config
+	}
+
+	if path := c.String(FlagModuleAllowlist); path != "" {
+		allowlist, err := checker.LoadModuleAllowlist(path)
+		if err != nil {
+			return nil, err
+		}
+		options.ModuleAllowlist = allowlist
+
+		severity, err := checker.ParseModuleAllowlistSeverity(c.String(FlagModuleAllowlistSeverity))
+		if err != nil {
+			return nil, err
+		}
+		options.ModuleAllowlistSeverity = severity
+	}
+
+	if path := c.String(FlagExceptionsFile); path != "" {
+		exceptions, err := checker.LoadExceptions(path)
+		if err != nil {
+			return nil, err
+		}
+		options.Exceptions = exceptions
+	}
+
+	return options, nil
+}
@@ -1,11 +1,13 @@
 package app
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
 	"github.com/longhorn/longhorn-preflight/pkg/installer"
 	"github.com/longhorn/longhorn-preflight/pkg/types"
 )
@@ -13,36 +15,133 @@ import (
 func PreflightInstallCmd(packageManager types.PackageManager) cli.Command {
 	return cli.Command{
 		Name:  "install",
-		Flags: []cli.Flag{},
+		Flags: checkerFlags(),
 		Usage: "Install and configure prerequisites",
 		Action: func(c *cli.Context) {
-			if err := install(c, packageManager); err != nil {
+			rebootRequired, err := install(c, packageManager)
+			if err != nil {
 				logrus.WithError(err).Fatalf("Failed to run command")
 			}
+			if rebootRequired {
+				os.Exit(ExitCodeRebootRequired)
+			}
 		},
 	}
 }
 
-func install(c *cli.Context, packageManager types.PackageManager) error {
+// nonRetryableError is an error of install which retrying does not fix, e.g.
+// the package manager is not supported
+type nonRetryableError struct {
+	error
+}
+
+func (e nonRetryableError) Unwrap() error {
+	return e.error
+}
+
+// install installs and configures the prerequisites, and returns whether the
+// node has to be rebooted for the installed kernel or the packages installed
+// in a new snapshot
+func install(c *cli.Context, packageManager types.PackageManager) (bool, error) {
+	options, err := newCheckerOptions(c, packageManager)
+	if err != nil {
+		return false, nonRetryableError{err}
+	}
+
+	logrus.Info("Checking preconditions")
+	if _, err := checker.CheckPreconditions(checker.NewPreconditions(options), options); err != nil {
+		return false, err
+	}
+	checker.CheckStepPreconditions(checker.NewStepPreconditions(options), options)
+	if options.IsPreconditionFailed(checker.PreconditionHostNamespace) {
+		return false, nonRetryableError{fmt.Errorf("cannot install anything without entering the host namespaces")}
+	}
+
 	installer, err := installer.NewInstaller(packageManager)
 	if err != nil {
-		return err
+		return false, nonRetryableError{err}
 	}
+	// The checkers install their packages with the same installer, so it
+	// knows whether any package was installed in a new snapshot
+	options.Installer = installer
 
-	if os.Getenv("UPDATE_PACKAGE_LIST") == "true" {
+	// transactional-update installs the packages in a new snapshot, which is
+	// writable even though /usr is read-only
+	skipPackages := options.IsPreconditionFailed(checker.PreconditionPackageLock) ||
+		(options.IsPreconditionFailed(checker.PreconditionUsrWritable) && !installer.IsTransactional())
+	if packageManager == types.PackageManagerTalos {
+		logrus.Info("Skipped installing packages since Talos installs them as system extensions")
+		skipPackages = true
+	} else if skipPackages {
+		logrus.Warn("Skipped installing packages since the package database is locked or /usr is read-only")
+	}
+
+	if os.Getenv("UPDATE_PACKAGE_LIST") == "true" && !skipPackages {
 		logrus.Info("Updating package list")
 		installer.UpdatePackageList()
 	}
 
 	logrus.Info("Modprobing required kernel modules")
-	installer.ProbeModules()
+	installer.ProbeModules(options.Requirements.ExcludedModules)
 
-	logrus.Info("Installing required packages for Longhorn")
-	installer.InstallPackages()
+	if !skipPackages {
+		logrus.Info("Installing required packages for Longhorn")
+		installer.InstallPackages()
+	}
 
-	if os.Getenv("ENABLE_SPDK") == "true" {
-		installer.InstallSPDKDeps()
+	if c.Bool(FlagEnableSPDK) {
+		if packageManager == types.PackageManagerTalos {
+			logrus.Warn("Skipped installing SPDK dependencies since Talos has no package manager")
+		} else if options.IsPreconditionFailed(checker.PreconditionPackageLock, checker.PreconditionUsrWritable, checker.PreconditionSysfsWritable) {
+			logrus.Warn("Skipped installing SPDK dependencies since the preconditions failed")
+		} else {
+			if err := installer.InstallSPDKDeps(options.HugePages, options.DriverOverride); err != nil {
+				logrus.WithError(err).Error("Failed to install SPDK dependencies")
+			}
+		}
 	}
 
-	return nil
+	kernelInstalled := false
+	if version := c.String(FlagInstallKernel); version != "" {
+		if skipPackages {
+			logrus.Warnf("Skipped installing kernel %v since the package database is locked or /usr is read-only", version)
+		} else {
+			logrus.Infof("Installing kernel %v", version)
+			pkg, installed, err := installer.InstallKernel(version)
+			if err != nil {
+				return false, fmt.Errorf("failed to install kernel %v: %v", version, err)
+			}
+			if installed {
+				logrus.Warnf("Installed kernel package %v, reboot the node to boot into it", pkg)
+				kernelInstalled = true
+			} else {
+				logrus.Infof("Kernel %v is already running or package %v is installed", version, pkg)
+			}
+		}
+	}
+
+	checkers, err := checker.NewCheckers(options)
+	if err != nil {
+		return false, nonRetryableError{err}
+	}
+
+	logrus.Info("Installing prerequisites of the enabled checks")
+	checker.Install(checkers, options)
+
+	if options.RebuildInitramfs {
+		if options.IsPreconditionFailed(checker.PreconditionBootSpace) {
+			logrus.Warn("Skipped rebuilding the initramfs since /boot does not have enough space")
+		} else {
+			logrus.Info("Rebuilding the initramfs")
+			if err := checker.RebuildInitramfs(options.Host, options.PackageManager); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if installer.IsRebootRequired() {
+		logrus.Warn("Changed the packages in a new snapshot, reboot the node to boot into it")
+		return true, nil
+	}
+	return kernelInstalled, nil
 }
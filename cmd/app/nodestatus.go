@@ -0,0 +1,63 @@
+package app
+
+import (
+	"time"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+	"github.com/longhorn/longhorn-preflight/pkg/kube"
+)
+
+const (
+	annotationResult    = "preflight.longhorn.io/result"
+	annotationSummary   = "preflight.longhorn.io/summary"
+	annotationTimestamp = "preflight.longhorn.io/timestamp"
+
+	nodeConditionType = "LonghornPreflightReady"
+)
+
+// nodeConditionReasons maps the overall results to the reasons of the node
+// condition
+var nodeConditionReasons = map[string]string{
+	"PASS":    "PreflightPassed",
+	"WARN":    "PreflightWarned",
+	"UNKNOWN": "PreflightUnknown",
+	"FAIL":    "PreflightFailed",
+}
+
+// writeNodeStatus writes the overall result and the summary to the
+// annotations of the node
+func writeNodeStatus(nodeName string, summary *checker.Summary) error {
+	client, err := kube.NewInClusterClient()
+	if err != nil {
+		return err
+	}
+	return client.PatchNodeAnnotations(nodeName, map[string]string{
+		annotationResult:    summary.Result(),
+		annotationSummary:   summary.String(),
+		annotationTimestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// writeNodeCondition sets the condition of the node to False if any check
+// failed, Unknown if any check cannot be evaluated, or True otherwise, with the
+// summary as the message
+func writeNodeCondition(nodeName string, summary *checker.Summary) error {
+	client, err := kube.NewInClusterClient()
+	if err != nil {
+		return err
+	}
+
+	status := "True"
+	switch {
+	case summary.Failed > 0:
+		status = "False"
+	case summary.Unknown > 0:
+		status = "Unknown"
+	}
+	return client.SetNodeCondition(nodeName, &kube.NodeCondition{
+		Type:    nodeConditionType,
+		Status:  status,
+		Reason:  nodeConditionReasons[summary.Result()],
+		Message: summary.String(),
+	})
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli"
+)
+
+// checkOutputFileWritable verifies the output file can be written before
+// running the checks, so the run does not fail at the end
+func checkOutputFileWritable(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("output file %v is not writable: %v", path, err)
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+
+	if _, err := os.Stat(path); err == nil {
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("output file %v is not writable: %v", path, err)
+		}
+		file.Close()
+	}
+	return nil
+}
+
+// checkOutputFilesWritable verifies the output file and the result file can
+// be written
+func checkOutputFilesWritable(c *cli.Context) error {
+	for _, name := range []string{FlagOutputFile, FlagResultFile} {
+		if path := c.String(name); path != "" {
+			if err := checkOutputFileWritable(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// openOutput returns the output file, or stdout if the path is empty
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
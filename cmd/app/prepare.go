@@ -0,0 +1,134 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const (
+	FlagMaxAttempts = "max-attempts"
+	FlagRetryDelay  = "retry-delay"
+
+	// ExitCodeRebootRequired is the exit code of prepare when the node is
+	// only ready after a reboot
+	ExitCodeRebootRequired = 2
+)
+
+func PreflightPrepareCmd(packageManager types.PackageManager) cli.Command {
+	return cli.Command{
+		Name: "prepare",
+		Flags: append(append(checkerFlags(), reportFlags()...),
+			cli.IntFlag{
+				Name:  FlagMaxAttempts,
+				Value: 3,
+				Usage: "Maximum number of install and check attempts",
+			},
+			cli.DurationFlag{
+				Name:  FlagRetryDelay,
+				Value: 10 * time.Second,
+				Usage: "Delay before the first retry, doubled before every further retry",
+			},
+		),
+		Usage: "Install the prerequisites and check the environment until the node is ready",
+		Action: func(c *cli.Context) {
+			rebootRequired, err := prepare(c, packageManager)
+			if err != nil {
+				logrus.WithError(err).Fatalf("Failed to run command")
+			}
+			if rebootRequired {
+				os.Exit(ExitCodeRebootRequired)
+			}
+		},
+	}
+}
+
+// prepare installs the prerequisites and checks the environment until no
+// check fails or the attempts are exhausted. It returns whether the node has
+// to be rebooted to become ready.
+func prepare(c *cli.Context, packageManager types.PackageManager) (bool, error) {
+	minSeverity, err := checker.ParseMinSeverity(c.String(FlagMinSeverity))
+	if err != nil {
+		return false, err
+	}
+	if err := checkOutputFilesWritable(c); err != nil {
+		return false, err
+	}
+
+	maxAttempts := c.Int(FlagMaxAttempts)
+	if maxAttempts < 1 {
+		return false, fmt.Errorf("invalid maximum number of attempts %d", maxAttempts)
+	}
+
+	// install returns whether the installed kernel or the packages installed
+	// in a new snapshot require a reboot
+	installRebootRequired := false
+	delay := c.Duration(FlagRetryDelay)
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			logrus.Infof("Retrying in %v", delay)
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		logrus.Infof("Preparing the node, attempt %d of %d", attempt, maxAttempts)
+		rebootRequired, err := install(c, packageManager)
+		if err != nil {
+			if !isRetryable(err) {
+				return false, fmt.Errorf("failed to install the prerequisites in attempt %d: %w", attempt, err)
+			}
+			logrus.WithError(err).Warnf("Failed to install the prerequisites in attempt %d", attempt)
+		}
+		installRebootRequired = installRebootRequired || rebootRequired
+
+		// The options record the failed preconditions, so every check starts afresh
+		options, err := newCheckerOptions(c, packageManager)
+		if err != nil {
+			return false, err
+		}
+		checkers, results, err := runChecks(options)
+		if err != nil {
+			if attempt < maxAttempts && isRetryable(err) {
+				logrus.WithError(err).Warnf("Failed to check the node in attempt %d", attempt)
+				continue
+			}
+			return false, fmt.Errorf("node is not ready after %d attempt(s): %w", attempt, err)
+		}
+
+		summary := checker.Summarize(results)
+		rebootRequired = installRebootRequired || checker.IsRebootRequired(checkers, results)
+		if summary.Failed == 0 && !rebootRequired {
+			logrus.Infof("Node is ready after %d attempt(s)", attempt)
+			return false, writeReport(c, options, checkers, results, minSeverity)
+		}
+		if rebootRequired || attempt == maxAttempts {
+			if err := writeReport(c, options, checkers, results, minSeverity); err != nil {
+				return false, err
+			}
+			if rebootRequired {
+				logrus.Warnf("Node requires a reboot to become ready after %d attempt(s)", attempt)
+				return true, nil
+			}
+			return false, fmt.Errorf("node is not ready after %d attempt(s): %d check(s) failed", attempt, summary.Failed)
+		}
+		logrus.Infof("%d check(s) failed in attempt %d, retrying", summary.Failed, attempt)
+	}
+}
+
+// isRetryable checks whether retrying may fix the error of installing or
+// checking the node. The failed preconditions are not fixed by retrying, except
+// the package database locked by another installation.
+func isRetryable(err error) bool {
+	var preconditionErr *checker.PreconditionError
+	if errors.As(err, &preconditionErr) {
+		return preconditionErr.Name == checker.PreconditionPackageLock
+	}
+	return !errors.As(err, &nonRetryableError{})
+}
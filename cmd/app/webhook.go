@@ -0,0 +1,37 @@
+package app
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/urfave/cli"
+)
+
+// getNodeName returns the node name from the downward API, or the hostname
+func getNodeName() string {
+	if name := os.Getenv("NODE_NAME"); name != "" {
+		return name
+	}
+	name, _ := os.Hostname()
+	return name
+}
+
+// getConfigHash returns the hash of the effective flags, which identifies
+// the configuration of the run. The cache flags are excluded since they do
+// not change the results.
+func getConfigHash(c *cli.Context) string {
+	names := c.FlagNames()
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		// The results of fixing are those of the check-only mode afterwards
+		if name == FlagCacheFile || name == FlagCacheMaxAge || name == FlagFix {
+			continue
+		}
+		fmt.Fprintf(h, "%v=%v\n", name, c.String(name))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
+}
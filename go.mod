@@ -7,6 +7,7 @@ require (
 	github.com/otiai10/copy v1.12.0
 	github.com/sirupsen/logrus v1.9.3
 	github.com/urfave/cli v1.22.14
+	golang.org/x/sys v0.11.0
 )
 
 require (
@@ -19,7 +20,6 @@ require (
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/shirou/gopsutil/v3 v3.23.7 // indirect
 	github.com/yusufpapurcu/wmi v1.2.3 // indirect
-	golang.org/x/sys v0.11.0 // indirect
 )
 
 replace github.com/longhorn/go-common-libs v0.0.0-20230725131218-5fe3b8fdf5d5 => github.com/c3y1huang/go-common-libs v0.0.0-20230908015436-886e1f60245c
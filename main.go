@@ -7,6 +7,7 @@ import (
 	"github.com/urfave/cli"
 
 	"github.com/longhorn/longhorn-preflight/cmd/app"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
 	"github.com/longhorn/longhorn-preflight/pkg/utils"
 )
 
@@ -15,22 +16,28 @@ func main() {
 	a.Name = "longhorn-preflight"
 	a.Usage = "longhorn-preflight helps users install prerequisites and check environment before installing Longhorn system"
 
+	// The platform is unknown when the host is not mounted, e.g. evaluating
+	// the facts collected from another node
+	packageManager := types.PackageManagerUnknown
 	platform, err := utils.GetOSRelease()
 	if err != nil {
-		logrus.WithError(err).Fatal("Failed to get OS release")
-	}
-
-	logrus.Infof("Detected platform: %s", platform)
-
-	packageManager, err := utils.GetPackageManager(platform)
-	if err != nil {
-		logrus.WithError(err).Fatal("Failed to get package manager")
+		logrus.WithError(err).Warn("Failed to get OS release")
+	} else {
+		logrus.Infof("Detected platform: %s", platform)
+
+		packageManager, err = utils.GetPackageManager(platform)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to get package manager")
+		}
 	}
 
 	a.Flags = []cli.Flag{}
 	a.Commands = []cli.Command{
 		app.PreflightInstallCmd(packageManager),
-		app.PreflightCheckCmd(),
+		app.PreflightCheckCmd(packageManager),
+		app.PreflightPrepareCmd(packageManager),
+		app.PreflightDiffCmd(),
+		app.PreflightAggregateCmd(),
 	}
 	if err := a.Run(os.Args); err != nil {
 		logrus.WithError(err).Fatal("Failed to execute command")
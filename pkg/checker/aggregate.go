@@ -0,0 +1,135 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NodeReport is the report of a node collected for the aggregation
+type NodeReport struct {
+	Node      string    `json:"node"`
+	Timestamp time.Time `json:"timestamp"`
+	*Report
+}
+
+// NodeSummary is the readiness of a node in the cluster report
+type NodeSummary struct {
+	Node      string    `json:"node"`
+	Result    string    `json:"result"`
+	Summary   *Summary  `json:"summary"`
+	Timestamp time.Time `json:"timestamp"`
+	// Failed are the IDs of the failed checks
+	Failed []string `json:"failed"`
+	// Stale is set when the report is older than the maximum age
+	Stale bool `json:"stale,omitempty"`
+	// Missing is set when the node has not written its report
+	Missing bool `json:"missing,omitempty"`
+}
+
+// ClusterReport summarizes the readiness of the nodes
+type ClusterReport struct {
+	Nodes    []*NodeSummary `json:"nodes"`
+	Total    int            `json:"total"`
+	Ready    int            `json:"ready"`
+	NotReady int            `json:"notReady"`
+	Stale    int            `json:"stale"`
+	Missing  int            `json:"missing"`
+}
+
+// AggregateReports returns the readiness of the nodes in the order of their
+// names. A node is ready when none of its checks failed and its report is not
+// older than maxAge, or of any age if maxAge is 0. If the names of the nodes
+// are given, only their reports are aggregated and the nodes without a report
+// are not ready.
+func AggregateReports(reports []*NodeReport, nodes []string, maxAge time.Duration, now time.Time) *ClusterReport {
+	cluster := &ClusterReport{Nodes: []*NodeSummary{}}
+	expected := map[string]bool{}
+	for _, node := range nodes {
+		expected[node] = true
+	}
+	reported := map[string]bool{}
+	for _, report := range reports {
+		if len(nodes) > 0 && !expected[report.Node] {
+			continue
+		}
+		reported[report.Node] = true
+		node := &NodeSummary{
+			Node:      report.Node,
+			Result:    report.Summary.Result(),
+			Summary:   report.Summary,
+			Timestamp: report.Timestamp,
+			Failed:    []string{},
+			Stale:     maxAge > 0 && now.Sub(report.Timestamp) > maxAge,
+		}
+		for _, result := range report.Results {
+			if result.Status == StatusFail {
+				node.Failed = append(node.Failed, result.ID())
+			}
+		}
+
+		switch {
+		case node.Stale:
+			cluster.Stale++
+		case report.Summary.Failed > 0:
+			cluster.NotReady++
+		default:
+			cluster.Ready++
+		}
+		cluster.Nodes = append(cluster.Nodes, node)
+	}
+	for _, name := range nodes {
+		if reported[name] {
+			continue
+		}
+		cluster.Missing++
+		cluster.Nodes = append(cluster.Nodes, &NodeSummary{
+			Node:    name,
+			Result:  "MISSING",
+			Failed:  []string{},
+			Missing: true,
+		})
+	}
+	cluster.Total = len(cluster.Nodes)
+	sort.Slice(cluster.Nodes, func(i, j int) bool {
+		return cluster.Nodes[i].Node < cluster.Nodes[j].Node
+	})
+	return cluster
+}
+
+// PrintClusterReport prints the cluster report in the output format, text or
+// json
+func PrintClusterReport(w io.Writer, output string, cluster *ClusterReport) error {
+	switch output {
+	case OutputText:
+		printClusterText(w, cluster)
+		return nil
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(cluster)
+	default:
+		return fmt.Errorf("unknown output format %v, must be one of text or json", output)
+	}
+}
+
+func printClusterText(w io.Writer, cluster *ClusterReport) {
+	for _, node := range cluster.Nodes {
+		if node.Missing {
+			fmt.Fprintf(w, "[%s] %s: no results\n", node.Result, node.Node)
+			continue
+		}
+		stale := ""
+		if node.Stale {
+			stale = " (stale)"
+		}
+		fmt.Fprintf(w, "[%s] %s%s: %s at %s\n", node.Result, node.Node, stale, node.Summary, node.Timestamp.Format(time.RFC3339))
+		if len(node.Failed) > 0 {
+			fmt.Fprintf(w, "  failed: %s\n", strings.Join(node.Failed, ", "))
+		}
+	}
+	fmt.Fprintf(w, "Cluster: %d of %d node(s) ready, %d not ready, %d stale, %d missing\n", cluster.Ready, cluster.Total, cluster.NotReady, cluster.Stale, cluster.Missing)
+}
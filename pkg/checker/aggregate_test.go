@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAggregateReports(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newReport := func(node string, age time.Duration, results ...*CheckResult) *NodeReport {
+		return &NodeReport{
+			Node:      node,
+			Timestamp: now.Add(-age),
+			Report:    &Report{Results: results, Summary: Summarize(results)},
+		}
+	}
+	pass := &CheckResult{Name: "kernel", Status: StatusPass}
+	fail := &CheckResult{Name: "iscsi", Status: StatusFail}
+
+	type node struct {
+		name    string
+		result  string
+		failed  []string
+		stale   bool
+		missing bool
+	}
+	tests := []struct {
+		name     string
+		reports  []*NodeReport
+		nodes    []string
+		maxAge   time.Duration
+		expected []node
+		ready    int
+		notReady int
+		stale    int
+		missing  int
+	}{
+		{
+			name:     "ready and failed nodes in the order of their names",
+			reports:  []*NodeReport{newReport("b", 0, pass, fail), newReport("a", 0, pass)},
+			expected: []node{{name: "a", result: "PASS", failed: []string{}}, {name: "b", result: "FAIL", failed: []string{"iscsi"}}},
+			ready:    1,
+			notReady: 1,
+		},
+		{
+			name:     "old report is stale",
+			reports:  []*NodeReport{newReport("a", 2*time.Hour, pass)},
+			maxAge:   time.Hour,
+			expected: []node{{name: "a", result: "PASS", failed: []string{}, stale: true}},
+			stale:    1,
+		},
+		{
+			name:     "old report is not stale without the maximum age",
+			reports:  []*NodeReport{newReport("a", 2*time.Hour, pass)},
+			expected: []node{{name: "a", result: "PASS", failed: []string{}}},
+			ready:    1,
+		},
+		{
+			name:     "listed node without a report is missing",
+			reports:  []*NodeReport{newReport("a", 0, pass)},
+			nodes:    []string{"a", "b"},
+			expected: []node{{name: "a", result: "PASS", failed: []string{}}, {name: "b", result: "MISSING", failed: []string{}, missing: true}},
+			ready:    1,
+			missing:  1,
+		},
+		{
+			name:     "report of a node not listed is ignored",
+			reports:  []*NodeReport{newReport("a", 0, pass), newReport("removed", 0, fail)},
+			nodes:    []string{"a"},
+			expected: []node{{name: "a", result: "PASS", failed: []string{}}},
+			ready:    1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := AggregateReports(test.reports, test.nodes, test.maxAge, now)
+
+			nodes := []node{}
+			for _, n := range cluster.Nodes {
+				nodes = append(nodes, node{name: n.Node, result: n.Result, failed: n.Failed, stale: n.Stale, missing: n.Missing})
+			}
+			if !reflect.DeepEqual(nodes, test.expected) {
+				t.Errorf("expected nodes %+v, got %+v", test.expected, nodes)
+			}
+			if cluster.Total != len(test.expected) || cluster.Ready != test.ready || cluster.NotReady != test.notReady ||
+				cluster.Stale != test.stale || cluster.Missing != test.missing {
+				t.Errorf("expected %d total, %d ready, %d not ready, %d stale, %d missing, got %+v",
+					len(test.expected), test.ready, test.notReady, test.stale, test.missing, cluster)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package checker
+
+import (
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// NewAttrToolsChecker returns the checker of chattr and lsattr used to
+// handle the immutable flag of the files
+func NewAttrToolsChecker(options *Options) *BinaryChecker {
+	return NewBinaryChecker("attr-tools", options, []string{"chattr", "lsattr"}, map[types.PackageManager]string{
+		types.PackageManagerApt:    "e2fsprogs",
+		types.PackageManagerYum:    "e2fsprogs",
+		types.PackageManagerZypper: "e2fsprogs",
+		types.PackageManagerApk:    "e2fsprogs-extra",
+		types.PackageManagerPacman: "e2fsprogs",
+	})
+}
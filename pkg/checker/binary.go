@@ -0,0 +1,86 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// BinaryChecker checks that the binaries exist on the host, and installs the
+// package providing them in install mode
+type BinaryChecker struct {
+	name           string
+	host           Host
+	packageManager types.PackageManager
+	installer      *installer.Installer
+
+	binaries []string
+	packages map[types.PackageManager]string
+}
+
+func NewBinaryChecker(name string, options *Options, binaries []string, packages map[types.PackageManager]string) *BinaryChecker {
+	return &BinaryChecker{
+		name:           name,
+		host:           options.Host,
+		packageManager: options.PackageManager,
+		installer:      options.Installer,
+		binaries:       binaries,
+		packages:       packages,
+	}
+}
+
+func (c *BinaryChecker) Name() string {
+	return c.name
+}
+
+func (c *BinaryChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionPackageLock, PreconditionUsrWritable}
+}
+
+func (c *BinaryChecker) Check() *CheckResult {
+	found := []string{}
+	missing := []string{}
+	for _, binary := range c.binaries {
+		path, err := lookPath(c.host, binary)
+		if err != nil {
+			missing = append(missing, binary)
+			continue
+		}
+		found = append(found, fmt.Sprintf("%v (%v)", binary, path))
+	}
+
+	if len(missing) > 0 {
+		return newResult(c.Name(), StatusWarn, "missing %v, install package %v", strings.Join(missing, ", "), c.packages[c.packageManager])
+	}
+	return newResult(c.Name(), StatusPass, "found %v", strings.Join(found, ", "))
+}
+
+func (c *BinaryChecker) Install() error {
+	pkg, ok := c.packages[c.packageManager]
+	if !ok {
+		return fmt.Errorf("no package providing %v for %v", strings.Join(c.binaries, ", "), c.packageManager)
+	}
+
+	return installPackage(c.installer, pkg)
+}
+
+func (c *BinaryChecker) Remediation() *Remediation {
+	pkg, ok := c.packages[c.packageManager]
+	if !ok {
+		return nil
+	}
+	return newInstallPackageRemediation(pkg)
+}
+
+// lookPath returns the path of the binary on the host
+func lookPath(host Host, binary string) (string, error) {
+	output, err := host.Execute("sh", []string{"-c", "command -v " + binary}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
@@ -0,0 +1,125 @@
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+// v2MaxBlockSize is the largest logical block size of the data device usable
+// by the v2 data engine, which lays out the data in 4KiB blocks
+const v2MaxBlockSize = 4096
+
+// nvmeLBAFormatRegexp matches the LBA formats in the output of nvme id-ns, e.g.
+// "lbaf  1 : ms:0   lbads:12 rp:0 (in use)"
+var nvmeLBAFormatRegexp = regexp.MustCompile(`lbaf\s+(\d+)\s*:\s*ms:(\d+)\s+lbads:(\d+).*?(\(in use\))?$`)
+
+type lbaFormat struct {
+	index        int
+	metadataSize int
+	blockSize    int
+	inUse        bool
+}
+
+func (f *lbaFormat) isCompatible() bool {
+	return f.metadataSize == 0 && f.blockSize <= v2MaxBlockSize
+}
+
+func (f *lbaFormat) String() string {
+	if f.metadataSize > 0 {
+		return fmt.Sprintf("%d+%d", f.blockSize, f.metadataSize)
+	}
+	return strconv.Itoa(f.blockSize)
+}
+
+// BlockSizeChecker checks that the logical block size of the data device is
+// usable by the v2 data engine. The NVMe namespaces are inspected with nvme
+// id-ns to suggest a compatible LBA format to switch to.
+type BlockSizeChecker struct {
+	host   Host
+	device string
+}
+
+func NewBlockSizeChecker(options *Options) *BlockSizeChecker {
+	return &BlockSizeChecker{
+		host:   options.Host,
+		device: filepath.Base(options.DataDevice),
+	}
+}
+
+func (c *BlockSizeChecker) Name() string {
+	return "block-size"
+}
+
+func (c *BlockSizeChecker) Check() *CheckResult {
+	if strings.HasPrefix(c.device, "nvme") {
+		return c.checkNVMe()
+	}
+
+	path := filepath.Join("/sys/block", c.device, "queue", "logical_block_size")
+	value, err := c.host.ReadFile(path)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", path, err)
+	}
+	blockSize, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the logical block size %q of %v: %v", value, c.device, err)
+	}
+	if blockSize > v2MaxBlockSize {
+		return newResult(c.Name(), StatusWarn, "%v has logical block size %d, the v2 data engine requires %d or smaller", c.device, blockSize, v2MaxBlockSize)
+	}
+	return newResult(c.Name(), StatusPass, "%v has logical block size %d", c.device, blockSize)
+}
+
+func (c *BlockSizeChecker) checkNVMe() *CheckResult {
+	output, err := c.host.Execute("nvme", []string{"id-ns", "/dev/" + c.device}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to identify the NVMe namespace %v: %v", c.device, err)
+	}
+
+	formats := []*lbaFormat{}
+	for _, line := range strings.Split(output, "\n") {
+		match := nvmeLBAFormatRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(match[1])
+		metadataSize, _ := strconv.Atoi(match[2])
+		lbads, _ := strconv.Atoi(match[3])
+		formats = append(formats, &lbaFormat{
+			index:        index,
+			metadataSize: metadataSize,
+			blockSize:    1 << lbads,
+			inUse:        match[4] != "",
+		})
+	}
+
+	var inUse, compatible *lbaFormat
+	supported := []string{}
+	for _, f := range formats {
+		supported = append(supported, f.String())
+		if f.inUse {
+			inUse = f
+		}
+		if compatible == nil && f.isCompatible() {
+			compatible = f
+		}
+	}
+	if inUse == nil {
+		return newResult(c.Name(), StatusWarn, "Failed to find the LBA format in use of %v", c.device)
+	}
+
+	if !inUse.isCompatible() {
+		hint := "none of the supported formats is compatible"
+		if compatible != nil {
+			hint = fmt.Sprintf("reformat it with nvme format --lbaf=%d, which erases the data", compatible.index)
+		}
+		return newResult(c.Name(), StatusWarn, "%v uses LBA format %v, the v2 data engine requires %d or smaller without metadata (supported: %v), %v",
+			c.device, inUse, v2MaxBlockSize, strings.Join(supported, ", "), hint)
+	}
+	return newResult(c.Name(), StatusPass, "%v uses LBA format %v (supported: %v)", c.device, inUse, strings.Join(supported, ", "))
+}
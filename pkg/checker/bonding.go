@@ -0,0 +1,110 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const procNetBondingDirectory = "/proc/net/bonding"
+
+// incompatibleBondingModes maps the bonding modes reported in
+// /proc/net/bonding, which reorder or rebalance the packets of a connection
+// across the slaves and make the iSCSI paths flap, to their short names
+var incompatibleBondingModes = map[string]string{
+	"load balancing (round-robin)": "balance-rr",
+	"fault-tolerance (broadcast)":  "broadcast",
+	"transmit load balancing":      "balance-tlb",
+	"adaptive load balancing":      "balance-alb",
+}
+
+// BondingChecker checks that the bonded storage interface, or every bond if
+// none is given, uses a bonding mode compatible with iSCSI multipath, e.g.
+// active-backup or 802.3ad
+type BondingChecker struct {
+	host  Host
+	iface string
+}
+
+func NewBondingChecker(options *Options) *BondingChecker {
+	return &BondingChecker{
+		host:  options.Host,
+		iface: options.StorageInterface,
+	}
+}
+
+func (c *BondingChecker) Name() string {
+	return "bonding-mode"
+}
+
+func (c *BondingChecker) Check() *CheckResult {
+	bonds := []string{c.iface}
+	if c.iface == "" {
+		var err error
+		if bonds, err = c.listBonds(); err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to list the bonds: %v", err)
+		}
+		if len(bonds) == 0 {
+			return newResult(c.Name(), StatusSkip, "No bonded interface")
+		}
+	}
+
+	status := StatusPass
+	modes := []string{}
+	for _, bond := range bonds {
+		path := filepath.Join(procNetBondingDirectory, bond)
+		exists, err := c.host.FileExists(path)
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to check %v: %v", path, err)
+		}
+		if !exists {
+			modes = append(modes, fmt.Sprintf("%v is not bonded", bond))
+			continue
+		}
+		content, err := c.host.ReadFile(path)
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", path, err)
+		}
+
+		mode := "unknown"
+		for _, line := range strings.Split(content, "\n") {
+			if value, ok := strings.CutPrefix(line, "Bonding Mode:"); ok {
+				mode = strings.TrimSpace(value)
+				break
+			}
+		}
+		if name, ok := incompatibleBondingModes[mode]; ok {
+			status = StatusWarn
+			modes = append(modes, fmt.Sprintf("%v uses %v (%v)", bond, mode, name))
+			continue
+		}
+		modes = append(modes, fmt.Sprintf("%v uses %v", bond, mode))
+	}
+
+	if status != StatusPass {
+		return newResult(c.Name(), status, "%v, which makes the iSCSI paths flap, use active-backup or 802.3ad for the storage network", strings.Join(modes, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "%v", strings.Join(modes, ", "))
+}
+
+func (c *BondingChecker) listBonds() ([]string, error) {
+	value, err := c.host.Probe("dir:"+procNetBondingDirectory, func() (string, error) {
+		entries, err := os.ReadDir(procNetBondingDirectory)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", nil
+			}
+			return "", err
+		}
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return strings.Join(names, " "), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(value), nil
+}
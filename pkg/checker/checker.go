@@ -0,0 +1,500 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+type Status string
+
+const (
+	StatusPass    = Status("pass")
+	StatusWarn    = Status("warn")
+	StatusFail    = Status("fail")
+	StatusSkip    = Status("skip")
+	StatusUnknown = Status("unknown")
+)
+
+var statusSeverity = map[Status]int{
+	StatusPass:    0,
+	StatusSkip:    0,
+	StatusWarn:    1,
+	StatusUnknown: 1,
+	StatusFail:    2,
+}
+
+const (
+	SeverityNone    = "none"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Severity returns the name of the severity of the status
+func (s Status) Severity() string {
+	switch statusSeverity[s] {
+	case 0:
+		return SeverityNone
+	case 1:
+		return SeverityWarning
+	default:
+		return SeverityError
+	}
+}
+
+// worstStatus returns the more severe one of the two statuses
+func worstStatus(a, b Status) Status {
+	if statusSeverity[b] > statusSeverity[a] {
+		return b
+	}
+	return a
+}
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	// Target is what the check is run against when it is run against several
+	// ones, e.g. the data path
+	Target string `json:"target,omitempty"`
+	// Details are the results of the targets grouped into this result
+	Details []*CheckResult `json:"details,omitempty"`
+	// Flaky is set when the status varied across the repeated runs
+	Flaky bool `json:"flaky,omitempty"`
+	// Stability notes how the status varied across the repeated runs
+	Stability string `json:"stability,omitempty"`
+	// Severity and Remediation are set by AnnotateResults for the machine
+	// readable reports
+	Severity    string `json:"severity,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// ID returns the name of the check, qualified by the target if any
+func (r *CheckResult) ID() string {
+	if r.Target == "" {
+		return r.Name
+	}
+	return r.Name + ":" + r.Target
+}
+
+// Checker checks a single prerequisite of the node
+type Checker interface {
+	Name() string
+	Check() *CheckResult
+}
+
+// Installer is implemented by the checkers able to install or configure the
+// checked prerequisite in install mode
+type Installer interface {
+	Install() error
+}
+
+// Rebooter is implemented by the checkers whose failures are only fixed by
+// rebooting the node, e.g. after the kernel parameters are changed
+type Rebooter interface {
+	IsRebootRequired() bool
+}
+
+// Targeted is implemented by the checkers run against one of several targets,
+// e.g. one of the data paths, whose results are grouped in the report when
+// they are identical
+type Targeted interface {
+	Target() string
+}
+
+// Dependent is implemented by the checkers depending on preconditions other
+// than entering the host namespaces, or not depending on it at all
+type Dependent interface {
+	Preconditions() []string
+}
+
+// getPreconditions returns the names of the preconditions the checker depends on
+func getPreconditions(c Checker) []string {
+	if d, ok := c.(Dependent); ok {
+		return d.Preconditions()
+	}
+	return []string{PreconditionHostNamespace}
+}
+
+// getFailedPrecondition returns the name and the reason of the first failed
+// precondition or check the checker depends on
+func getFailedPrecondition(c Checker, options *Options) (string, string, bool) {
+	for _, name := range getPreconditions(c) {
+		if reason, ok := options.FailedPreconditions[name]; ok {
+			return name, reason, true
+		}
+		if reason, ok := options.FailedChecks[name]; ok {
+			return name, reason, true
+		}
+	}
+	return "", "", false
+}
+
+// Options contains the settings used to select and configure the checkers
+type Options struct {
+	ProcName       string
+	PackageManager types.PackageManager
+	NodeName       string
+
+	// Installer installs the packages of the checkers in install mode. It is
+	// shared by them to know whether the packages were installed in a new
+	// snapshot, which requires a reboot.
+	Installer *installer.Installer
+
+	// Host gathers the data evaluated by the checkers
+	Host Host
+
+	Config *Config
+
+	// Requirements are the prerequisites of the targeted Longhorn release
+	Requirements *Requirements
+	// LonghornVersionSet is set when the release is given rather than
+	// defaulting to the latest one
+	LonghornVersionSet bool
+
+	EnableSPDK     bool
+	DriverOverride string
+	PersistIOMMU   bool
+	// NVMeTransport is the NVMe-oF transport of the v2 data engine: tcp or rdma
+	NVMeTransport    string
+	EnableEncryption bool
+
+	// HugePages is the number of 2MiB HugePages for the v2 data engine
+	HugePages        int
+	PersistHugePages bool
+	SetHugePages     bool
+
+	// RequireAVX2 requires the CPU to support AVX2 besides SSE4.2 for SPDK
+	RequireAVX2 bool
+
+	// MinShmSize is the minimum size of /dev/shm in MiB, or 0 for the default
+	// depending on the data engine
+	MinShmSize int
+
+	// MinPidMax is the minimum kernel.pid_max
+	MinPidMax int
+
+	// MinInotifyWatches and MinInotifyInstances are the minimum
+	// fs.inotify.max_user_watches and fs.inotify.max_user_instances
+	MinInotifyWatches   int
+	MinInotifyInstances int
+
+	// DevPath is where the host /dev is expected to be mounted in the container
+	DevPath string
+
+	// DiskPaths are the paths of the Longhorn data on the node
+	DiskPaths []string
+
+	// KubeletRootDir is the root directory of the kubelet
+	KubeletRootDir string
+
+	// DataDevice is the block device storing the Longhorn data, e.g. /dev/nvme0n1
+	DataDevice     string
+	SetIOScheduler bool
+
+	// RebuildInitramfs rebuilds the initramfs at the end of the installation
+	RebuildInitramfs bool
+
+	// PersistModules persists the required modules to be loaded on boot
+	PersistModules bool
+
+	// CleanupStaleDM removes the stale device-mapper devices of the Longhorn
+	// volumes in install mode
+	CleanupStaleDM bool
+
+	CheckJQ               bool
+	CheckSG3              bool
+	CheckWipefs           bool
+	CheckTun              bool
+	CheckAttrTools        bool
+	CheckFuse             bool
+	CheckIrqbalance       bool
+	CheckRawSocket        bool
+	CheckHostsFile        bool
+	CheckNUMA             bool
+	CheckHWRNG            bool
+	CheckKubeletDelegate  bool
+	CheckFileCapabilities bool
+	CheckTuned            bool
+	CheckRTC              bool
+	CheckNBD              bool
+	CheckHTTPTools        bool
+	CheckBonding          bool
+	CheckFSMaint          bool
+
+	// StorageInterface is the network interface of the storage network
+	StorageInterface string
+
+	// NbdsMax is the number of the NBD devices created when loading nbd
+	NbdsMax int
+
+	// BackupTargetURL is the HTTP URL of the backup target probed by the
+	// http-tools check, or empty on the air-gapped nodes
+	BackupTargetURL string
+
+	// RequiredHostnames are the hostnames /etc/hosts must resolve
+	RequiredHostnames []string
+
+	// ModuleAllowlist is the set of the approved kernel modules, or nil if the
+	// loaded modules are not checked against it
+	ModuleAllowlist         map[string]bool
+	ModuleAllowlistSeverity Status
+
+	// PluginDir is the directory of the executables run as external checks
+	PluginDir string
+
+	// WriteNodeStatus writes the summary of the results to the annotations of
+	// the node
+	WriteNodeStatus bool
+	// WriteNodeCondition sets the condition of the overall result in the
+	// status of the node
+	WriteNodeCondition bool
+
+	// ResultsConfigMap is the ConfigMap in ResultsConfigMapNamespace the report
+	// is written to, keyed by the node name, for aggregating the results of
+	// the nodes
+	ResultsConfigMap          string
+	ResultsConfigMapNamespace string
+
+	// Exceptions maps the names of the checks skipped on this node to the reasons
+	Exceptions map[string]string
+
+	// StabilityRuns is the number of times each check is run to detect the
+	// flaky ones
+	StabilityRuns int
+
+	// BestEffort continues when preconditions fail, and reports the checks
+	// depending on them as unknown
+	BestEffort bool
+	// FailedPreconditions maps the names of the failed preconditions to the
+	// reasons
+	FailedPreconditions map[string]string
+	// FailedChecks maps the names of the failed checks to the reasons, which
+	// the checks depending on them consult
+	FailedChecks map[string]string
+}
+
+func NewOptions(packageManager types.PackageManager) *Options {
+	return &Options{
+		ProcName:       lhtypes.ProcessNone,
+		PackageManager: packageManager,
+		Host:           NewLiveHost(lhtypes.ProcessNone),
+		Config:         NewConfig(),
+		Requirements:   mustGetRequirements(),
+		DevPath:        "/dev",
+		DiskPaths:      []string{defaultDiskPath},
+		KubeletRootDir: defaultKubeletRootDir,
+		HugePages:      1024,
+		MinPidMax:      65536,
+		NbdsMax:        defaultNbdsMax,
+
+		MinInotifyWatches:   524288,
+		MinInotifyInstances: 512,
+
+		ModuleAllowlistSeverity: StatusWarn,
+		Exceptions:              map[string]string{},
+
+		FailedPreconditions: map[string]string{},
+		FailedChecks:        map[string]string{},
+	}
+}
+
+// IsPreconditionFailed checks whether any of the preconditions failed in
+// best-effort mode
+func (o *Options) IsPreconditionFailed(names ...string) bool {
+	for _, name := range names {
+		if _, ok := o.FailedPreconditions[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs the checkers one by one and returns their results. The checkers
+// listed in the exceptions are not run and reported as skipped, and the
+// checkers depending on failed preconditions or checks are reported as
+// unknown. When evaluating the facts, the checkers missing some facts are
+// reported as unknown.
+func Run(checkers []Checker, options *Options) []*CheckResult {
+	results := []*CheckResult{}
+	for _, c := range checkers {
+		result := runChecker(c, options)
+		if t, ok := c.(Targeted); ok {
+			result.Target = t.Target()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// runChecker runs the checker unless it is excepted or its preconditions
+// failed
+func runChecker(c Checker, options *Options) *CheckResult {
+	if reason, ok := options.Exceptions[c.Name()]; ok {
+		return newResult(c.Name(), StatusSkip, "skipped (exception): %v", reason)
+	}
+	if name, reason, failed := getFailedPrecondition(c, options); failed {
+		return newResult(c.Name(), StatusUnknown, "precondition %v failed: %v", name, reason)
+	}
+
+	result := c.Check()
+	if result.Name == "" {
+		result.Name = c.Name()
+	}
+	if facts, ok := options.Host.(*FactsHost); ok {
+		if missing := facts.MissingFacts(); len(missing) > 0 {
+			result = newResult(c.Name(), StatusUnknown, "cannot be evaluated due to missing facts: %v", strings.Join(missing, ", "))
+		}
+	}
+	if result.Status == StatusFail {
+		options.FailedChecks[result.Name] = result.Message
+	}
+	return result
+}
+
+func newResult(name string, status Status, format string, args ...interface{}) *CheckResult {
+	return &CheckResult{
+		Name:    name,
+		Status:  status,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// CheckPreconditions runs the preconditions one by one and returns an error
+// for the first one that fails. In best-effort mode, the failures are
+// recorded in the options instead.
+func CheckPreconditions(preconditions []Checker, options *Options) ([]*CheckResult, error) {
+	results := []*CheckResult{}
+	for _, c := range preconditions {
+		result := Run([]Checker{c}, options)[0]
+		results = append(results, result)
+
+		switch result.Status {
+		case StatusFail, StatusUnknown:
+			if !options.BestEffort {
+				return results, &PreconditionError{Name: result.Name, Message: result.Message}
+			}
+			logrus.Warnf("Precondition %v failed, continuing in best-effort mode: %v", result.Name, result.Message)
+			options.FailedPreconditions[result.Name] = result.Message
+		default:
+			logrus.Infof("Precondition %v: %v", result.Name, result.Message)
+		}
+	}
+	return results, nil
+}
+
+// PreconditionError is returned when a precondition fails outside the
+// best-effort mode
+type PreconditionError struct {
+	Name    string
+	Message string
+}
+
+func (e *PreconditionError) Error() string {
+	return fmt.Sprintf("precondition %v failed: %v", e.Name, e.Message)
+}
+
+// CheckStepPreconditions runs the preconditions of the steps one by one and
+// records the failures in the options, so the steps depending on them are
+// skipped while the rest of the installation goes on
+func CheckStepPreconditions(preconditions []Checker, options *Options) []*CheckResult {
+	results := []*CheckResult{}
+	for _, c := range preconditions {
+		result := Run([]Checker{c}, options)[0]
+		results = append(results, result)
+
+		switch result.Status {
+		case StatusFail, StatusUnknown:
+			logrus.Warnf("Precondition %v failed, skipping the steps depending on it: %v", result.Name, result.Message)
+			options.FailedPreconditions[result.Name] = result.Message
+		default:
+			logrus.Infof("Precondition %v: %v", result.Name, result.Message)
+		}
+	}
+	return results
+}
+
+// errInstallDisabled is wrapped in the error returned by the installers whose
+// fixing is opt-in and not enabled, which are reported as skipped
+var errInstallDisabled = errors.New("not enabled")
+
+// Install runs the installation of the checkers whose check warns or fails
+func Install(checkers []Checker, options *Options) {
+	for _, c := range checkers {
+		i, ok := c.(Installer)
+		if !ok {
+			continue
+		}
+		if _, ok := options.Exceptions[c.Name()]; ok {
+			continue
+		}
+		if name, reason, failed := getFailedPrecondition(c, options); failed {
+			logrus.Warnf("Skipped installing %v since precondition %v failed: %v", c.Name(), name, reason)
+			continue
+		}
+		// Only the warnings and failures are fixed, the skipped and unknown
+		// checks are not applicable to the host or cannot be evaluated
+		if result := c.Check(); result.Status != StatusWarn && result.Status != StatusFail {
+			continue
+		}
+
+		logrus.Infof("Installing %v", c.Name())
+		if err := i.Install(); errors.Is(err, errInstallDisabled) {
+			logrus.Infof("Skipped installing %v since %v", c.Name(), err)
+		} else if err != nil {
+			logrus.WithError(err).Errorf("Failed to install %v", c.Name())
+		} else {
+			logrus.Infof("Successfully installed %v", c.Name())
+		}
+	}
+}
+
+// IsRebootRequired checks whether any of the checks not passing requires
+// rebooting the node
+func IsRebootRequired(checkers []Checker, results []*CheckResult) bool {
+	return len(GetRebootRequiredChecks(checkers, results)) > 0
+}
+
+// GetRebootRequiredChecks returns the names of the checks not passing which
+// require rebooting the node
+func GetRebootRequiredChecks(checkers []Checker, results []*CheckResult) map[string]bool {
+	rebooters := map[string]Rebooter{}
+	for _, c := range checkers {
+		if r, ok := c.(Rebooter); ok {
+			rebooters[c.Name()] = r
+		}
+	}
+
+	names := map[string]bool{}
+	for _, result := range results {
+		if result.Status != StatusWarn && result.Status != StatusFail {
+			continue
+		}
+		if r, ok := rebooters[result.Name]; ok && r.IsRebootRequired() {
+			names[result.Name] = true
+		}
+	}
+	return names
+}
+
+// formatBytes returns the size in a human-readable binary unit
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
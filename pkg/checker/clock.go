@@ -0,0 +1,67 @@
+package checker
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const maxClockDifference = 2 * time.Second
+
+// ClockChecker verifies the clock of the container matches the host clock,
+// otherwise the time-based checks are misleading
+type ClockChecker struct {
+	host Host
+}
+
+func NewClockChecker(options *Options) *ClockChecker {
+	return &ClockChecker{
+		host: options.Host,
+	}
+}
+
+func (c *ClockChecker) Name() string {
+	return "container-clock"
+}
+
+func (c *ClockChecker) Check() *CheckResult {
+	before, err := c.getSelfTime()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the container time: %v", err)
+	}
+
+	output, err := c.host.Execute("date", []string{"+%s.%N"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the host time: %v", err)
+	}
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the host time %q: %v", output, err)
+	}
+	hostTime := time.Unix(0, int64(seconds*float64(time.Second)))
+
+	after, err := c.getSelfTime()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the container time: %v", err)
+	}
+
+	// Compare against the midpoint to exclude the time spent on running date
+	selfTime := before.Add(after.Sub(before) / 2)
+	difference := hostTime.Sub(selfTime).Round(time.Millisecond)
+	if difference.Abs() > maxClockDifference {
+		return newResult(c.Name(), StatusWarn, "container clock differs from the host clock by %v", difference)
+	}
+	return newResult(c.Name(), StatusPass, "container clock differs from the host clock by %v", difference)
+}
+
+func (c *ClockChecker) getSelfTime() (time.Time, error) {
+	value, err := c.host.ProbeSelf("self-time", func() (string, error) {
+		return time.Now().Format(time.RFC3339Nano), nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config is the configuration file of the checks
+type Config struct {
+	Scoring ScoringConfig `json:"scoring"`
+	Iscsi   IscsiConfig   `json:"iscsi"`
+}
+
+// ScoringConfig configures the node grade. The checks have the weight 1
+// unless configured, and a failed mandatory check caps the grade at F.
+type ScoringConfig struct {
+	Weights   map[string]float64 `json:"weights"`
+	Mandatory []string           `json:"mandatory"`
+}
+
+// IscsiConfig configures the recommended iSCSI settings
+type IscsiConfig struct {
+	// ConnTimeouts maps the node.conn[0].timeo settings of iscsid.conf to the
+	// recommended values
+	ConnTimeouts map[string]string `json:"connTimeouts"`
+}
+
+func NewConfig() *Config {
+	connTimeouts := map[string]string{}
+	for key, value := range defaultIscsiConnTimeouts {
+		connTimeouts[key] = value
+	}
+
+	return &Config{
+		Scoring: ScoringConfig{
+			Weights:   map[string]float64{},
+			Mandatory: []string{},
+		},
+		Iscsi: IscsiConfig{
+			ConnTimeouts: connTimeouts,
+		},
+	}
+}
+
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := NewConfig()
+	if err := json.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %v: %v", path, err)
+	}
+	return config, nil
+}
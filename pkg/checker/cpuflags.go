@@ -0,0 +1,75 @@
+package checker
+
+import "strings"
+
+// CPUFlagsChecker checks that the CPU supports the instruction sets SPDK is
+// built for, since the v2 data engine crashes on illegal instructions on the
+// older CPUs rather than reporting an error
+type CPUFlagsChecker struct {
+	host  Host
+	flags []string
+}
+
+func NewCPUFlagsChecker(options *Options) *CPUFlagsChecker {
+	flags := []string{"sse4_2"}
+	if options.RequireAVX2 {
+		flags = append(flags, "avx2")
+	}
+	return &CPUFlagsChecker{
+		host:  options.Host,
+		flags: flags,
+	}
+}
+
+func (c *CPUFlagsChecker) Name() string {
+	return "cpu-flags"
+}
+
+func (c *CPUFlagsChecker) Check() *CheckResult {
+	content, err := c.host.ReadFile(procCPUInfoPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", procCPUInfoPath, err)
+	}
+
+	// Only x86 lists the instruction sets as flags, the other architectures
+	// list them as features
+	supported := map[string]bool{}
+	found := false
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		found = true
+		for _, flag := range strings.Fields(value) {
+			supported[flag] = true
+		}
+		// The flags of the first CPU stand for all of them
+		break
+	}
+	if !found {
+		return newResult(c.Name(), StatusSkip, "%v does not list the CPU flags, which are only checked on x86", procCPUInfoPath)
+	}
+
+	missing := []string{}
+	for _, flag := range c.flags {
+		if !supported[flag] {
+			missing = append(missing, describeCPUFlag(flag))
+		}
+	}
+	if len(missing) > 0 {
+		return newResult(c.Name(), StatusFail, "CPU does not support %v required by SPDK of the v2 data engine", strings.Join(missing, ", "))
+	}
+
+	described := []string{}
+	for _, flag := range c.flags {
+		described = append(described, describeCPUFlag(flag))
+	}
+	return newResult(c.Name(), StatusPass, "CPU supports %v", strings.Join(described, ", "))
+}
+
+// describeCPUFlag returns the name of the instruction set of the CPU flag,
+// e.g. SSE4.2 for sse4_2
+func describeCPUFlag(flag string) string {
+	return strings.ToUpper(strings.ReplaceAll(flag, "_", "."))
+}
@@ -0,0 +1,56 @@
+package checker
+
+import (
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+)
+
+// minCryptsetupVersion is the first cryptsetup version defaulting to LUKS2
+const minCryptsetupVersion = "2.1.0"
+
+// CryptsetupChecker checks that cryptsetup supports LUKS2 used by the
+// encrypted volumes, and updates it in install mode
+type CryptsetupChecker struct {
+	host      Host
+	installer *installer.Installer
+}
+
+func NewCryptsetupChecker(options *Options) *CryptsetupChecker {
+	return &CryptsetupChecker{
+		host:      options.Host,
+		installer: options.Installer,
+	}
+}
+
+func (c *CryptsetupChecker) Name() string {
+	return "cryptsetup"
+}
+
+func (c *CryptsetupChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionPackageLock, PreconditionUsrWritable}
+}
+
+func (c *CryptsetupChecker) Check() *CheckResult {
+	output, err := c.host.Execute(lhtypes.BinaryCryptsetup, []string{"--version"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the cryptsetup version: %v", err)
+	}
+
+	version := parseVersion(output)
+	if version == "" {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the cryptsetup version from %q", output)
+	}
+	if compareVersions(version, minCryptsetupVersion) < 0 {
+		return newResult(c.Name(), StatusWarn, "cryptsetup %v does not default to LUKS2, requires %v or later", version, minCryptsetupVersion)
+	}
+	return newResult(c.Name(), StatusPass, "cryptsetup %v supports LUKS2", version)
+}
+
+func (c *CryptsetupChecker) Remediation() *Remediation {
+	return newInstallPackageRemediation("cryptsetup")
+}
+
+func (c *CryptsetupChecker) Install() error {
+	return installPackage(c.installer, "cryptsetup")
+}
@@ -0,0 +1,80 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// daemonSetEnvs maps the environment variables required in the DaemonSet to
+// the guidance for setting them
+var daemonSetEnvs = map[string]string{
+	"NODE_NAME": "set it from the downward API with valueFrom.fieldRef.fieldPath: spec.nodeName",
+}
+
+// DaemonSetEnvChecker checks that the DaemonSet manifest provides the
+// environment variables and the host root mount the tool relies on when
+// running in-cluster
+type DaemonSetEnvChecker struct {
+	host Host
+}
+
+func NewDaemonSetEnvChecker(options *Options) *DaemonSetEnvChecker {
+	return &DaemonSetEnvChecker{
+		host: options.Host,
+	}
+}
+
+func (c *DaemonSetEnvChecker) Name() string {
+	return "daemonset-env"
+}
+
+func (c *DaemonSetEnvChecker) Preconditions() []string {
+	return nil
+}
+
+func (c *DaemonSetEnvChecker) Check() *CheckResult {
+	inCluster, err := c.host.ProbeSelf("self-exists:"+serviceAccountTokenPath, func() (string, error) {
+		_, err := os.Stat(serviceAccountTokenPath)
+		return fmt.Sprint(err == nil), nil
+	})
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to check whether running in-cluster: %v", err)
+	}
+	if inCluster != "true" {
+		return newResult(c.Name(), StatusSkip, "not running in-cluster")
+	}
+
+	missing := []string{}
+	for name, guidance := range daemonSetEnvs {
+		value, err := c.host.ProbeSelf("self-env:"+name, func() (string, error) {
+			return os.Getenv(name), nil
+		})
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to get environment variable %v: %v", name, err)
+		}
+		if value == "" {
+			missing = append(missing, fmt.Sprintf("%v (%v)", name, guidance))
+		}
+	}
+
+	hostRoot, err := c.host.ProbeSelf("self-exists:"+types.HostRootDirectory+"/proc", func() (string, error) {
+		_, err := os.Stat(types.HostRootDirectory + "/proc")
+		return fmt.Sprint(err == nil), nil
+	})
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to check the host root mount: %v", err)
+	}
+	if hostRoot != "true" {
+		missing = append(missing, fmt.Sprintf("host root (mount the host / at %v with a hostPath volume)", types.HostRootDirectory))
+	}
+
+	if len(missing) > 0 {
+		return newResult(c.Name(), StatusFail, "The DaemonSet misses %v", strings.Join(missing, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "The DaemonSet provides the required environment variables and the host root mount")
+}
@@ -0,0 +1,106 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	lhutils "github.com/longhorn/go-common-libs/utils"
+)
+
+// DevfsChecker verifies the dev directory of the container is the host
+// devtmpfs by comparing the device files against the block devices in the
+// host sysfs. Otherwise, the block devices seen by the tool are wrong.
+type DevfsChecker struct {
+	host    Host
+	devPath string
+}
+
+func NewDevfsChecker(options *Options) *DevfsChecker {
+	return &DevfsChecker{
+		host:    options.Host,
+		devPath: options.DevPath,
+	}
+}
+
+func (c *DevfsChecker) Name() string {
+	return "devfs"
+}
+
+func (c *DevfsChecker) Check() *CheckResult {
+	fsType, err := c.host.ProbeSelf("self-fstype:"+c.devPath, func() (string, error) {
+		return getSelfFSType(c.devPath)
+	})
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the filesystem type of %v: %v", c.devPath, err)
+	}
+
+	hostDevices, err := c.getHostBlockDevices()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the block devices from the host sysfs: %v", err)
+	}
+
+	missing := []string{}
+	for _, name := range hostDevices {
+		path := filepath.Join(c.devPath, name)
+		exists, err := c.host.ProbeSelf("self-exists:"+path, func() (string, error) {
+			_, err := os.Stat(path)
+			return fmt.Sprint(err == nil), nil
+		})
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to check %v: %v", path, err)
+		}
+		if exists != "true" {
+			missing = append(missing, name)
+		}
+	}
+
+	if fsType != "devtmpfs" || len(missing) > 0 {
+		return newResult(c.Name(), StatusWarn,
+			"%v is %v and misses %d of %d host block devices (%v), mount the host /dev into the container with a hostPath volume",
+			c.devPath, fsType, len(missing), len(hostDevices), strings.Join(missing, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "%v is devtmpfs with all %d host block devices", c.devPath, len(hostDevices))
+}
+
+func (c *DevfsChecker) getHostBlockDevices() ([]string, error) {
+	value, err := c.host.Probe("block-devices", func() (string, error) {
+		devices, err := lhutils.GetSystemBlockDeviceInfo()
+		if err != nil {
+			return "", err
+		}
+		names := []string{}
+		for name := range devices {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return strings.Join(names, "\n"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(value), nil
+}
+
+// getSelfFSType returns the type of the filesystem mounted at the path in the
+// mount namespace of this process
+func getSelfFSType(path string) (string, error) {
+	content, err := os.ReadFile(procMountsPath)
+	if err != nil {
+		return "", err
+	}
+
+	fsType := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[1] == path {
+			fsType = fields[2]
+		}
+	}
+	if fsType == "" {
+		return "", fmt.Errorf("%v is not a mount point", path)
+	}
+	return fsType, nil
+}
@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ResultDiff is the difference of a check between two reports. The result is
+// nil when the check is missing from the report, e.g. gated by other flags.
+type ResultDiff struct {
+	Name string       `json:"name"`
+	A    *CheckResult `json:"a"`
+	B    *CheckResult `json:"b"`
+}
+
+// LoadReport reads the report written in JSON
+func LoadReport(path string) (*Report, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	if err := json.Unmarshal(content, report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %v: %v", path, err)
+	}
+	return report, nil
+}
+
+// DiffResults returns the checks whose status or message differs between the
+// results, or which are present in only one of them, in the order of a and
+// then b
+func DiffResults(a, b []*CheckResult) []*ResultDiff {
+	byID := map[string]*CheckResult{}
+	for _, result := range b {
+		byID[result.ID()] = result
+	}
+
+	diffs := []*ResultDiff{}
+	seen := map[string]bool{}
+	for _, result := range a {
+		seen[result.ID()] = true
+		other := byID[result.ID()]
+		if other != nil && other.Status == result.Status && other.Message == result.Message {
+			continue
+		}
+		diffs = append(diffs, &ResultDiff{Name: result.ID(), A: result, B: other})
+	}
+	for _, result := range b {
+		if !seen[result.ID()] {
+			diffs = append(diffs, &ResultDiff{Name: result.ID(), B: result})
+		}
+	}
+	return diffs
+}
+
+// PrintDiffs prints the differences in the output format, text or json
+func PrintDiffs(w io.Writer, output, nameA, nameB string, diffs []*ResultDiff) error {
+	switch output {
+	case OutputText:
+		printDiffsText(w, nameA, nameB, diffs)
+		return nil
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diffs)
+	default:
+		return fmt.Errorf("unknown output format %v, must be one of text or json", output)
+	}
+}
+
+func printDiffsText(w io.Writer, nameA, nameB string, diffs []*ResultDiff) {
+	for _, diff := range diffs {
+		switch {
+		case diff.B == nil:
+			fmt.Fprintf(w, "%s: only in %s\n", diff.Name, nameA)
+			fmt.Fprintf(w, "  %s: [%s] %s\n", nameA, diff.A.Status, diff.A.Message)
+		case diff.A == nil:
+			fmt.Fprintf(w, "%s: only in %s\n", diff.Name, nameB)
+			fmt.Fprintf(w, "  %s: [%s] %s\n", nameB, diff.B.Status, diff.B.Message)
+		default:
+			if diff.A.Status != diff.B.Status {
+				fmt.Fprintf(w, "%s: status %s -> %s\n", diff.Name, diff.A.Status, diff.B.Status)
+			} else {
+				fmt.Fprintf(w, "%s: message differs\n", diff.Name)
+			}
+			fmt.Fprintf(w, "  %s: [%s] %s\n", nameA, diff.A.Status, diff.A.Message)
+			fmt.Fprintf(w, "  %s: [%s] %s\n", nameB, diff.B.Status, diff.B.Message)
+		}
+	}
+	fmt.Fprintf(w, "%d check(s) differ\n", len(diffs))
+}
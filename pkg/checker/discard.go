@@ -0,0 +1,42 @@
+package checker
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DiscardChecker checks that the data device supports discard, which is
+// needed to reclaim the space of the deleted data
+type DiscardChecker struct {
+	host   Host
+	device string
+}
+
+func NewDiscardChecker(options *Options) *DiscardChecker {
+	return &DiscardChecker{
+		host:   options.Host,
+		device: filepath.Base(options.DataDevice),
+	}
+}
+
+func (c *DiscardChecker) Name() string {
+	return "discard"
+}
+
+func (c *DiscardChecker) Check() *CheckResult {
+	path := filepath.Join("/sys/block", c.device, "queue", "discard_max_bytes")
+	content, err := c.host.ReadFile(path)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the discard capability of %v: %v", c.device, err)
+	}
+
+	maxBytes, err := strconv.ParseInt(strings.TrimSpace(content), 10, 64)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse %v: %v", path, err)
+	}
+	if maxBytes == 0 {
+		return newResult(c.Name(), StatusWarn, "%v does not support discard, the space of the deleted data cannot be reclaimed", c.device)
+	}
+	return newResult(c.Name(), StatusPass, "%v supports discard up to %v per request", c.device, formatBytes(maxBytes))
+}
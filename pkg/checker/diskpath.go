@@ -0,0 +1,70 @@
+package checker
+
+import (
+	"strings"
+)
+
+const defaultDiskPath = "/var/lib/longhorn"
+
+// networkFSTypes are the network filesystem types, including the FUSE ones
+// reported as fuse.<name>
+var networkFSTypes = map[string]bool{
+	"nfs":            true,
+	"nfs4":           true,
+	"cifs":           true,
+	"smb3":           true,
+	"smbfs":          true,
+	"ceph":           true,
+	"glusterfs":      true,
+	"lustre":         true,
+	"afs":            true,
+	"9p":             true,
+	"fuse.sshfs":     true,
+	"fuse.glusterfs": true,
+	"fuse.s3fs":      true,
+	"fuse.rclone":    true,
+	"fuse.cephfs":    true,
+	"fuse.davfs":     true,
+}
+
+// DiskPathChecker checks that the Longhorn data path is on a local
+// filesystem, since the replicas on a network filesystem defeat the purpose
+// of Longhorn and are unstable
+type DiskPathChecker struct {
+	host     Host
+	diskPath string
+}
+
+func NewDiskPathChecker(options *Options, diskPath string) *DiskPathChecker {
+	return &DiskPathChecker{
+		host:     options.Host,
+		diskPath: diskPath,
+	}
+}
+
+func (c *DiskPathChecker) Name() string {
+	return "disk-path-filesystem"
+}
+
+func (c *DiskPathChecker) Target() string {
+	return c.diskPath
+}
+
+func (c *DiskPathChecker) Check() *CheckResult {
+	mounts, err := getMounts(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get mounts: %v", err)
+	}
+
+	m := findMount(mounts, c.diskPath)
+	if m == nil {
+		return newResult(c.Name(), StatusWarn, "Failed to find the mount of %v", c.diskPath)
+	}
+
+	fsType := strings.ToLower(m.FSType)
+	if networkFSTypes[fsType] || strings.HasPrefix(fsType, "nfs") {
+		return newResult(c.Name(), StatusFail, "%v is on network filesystem %v from %v (mounted at %v), use a local disk for the Longhorn data",
+			c.diskPath, m.FSType, m.Source, m.MountPoint)
+	}
+	return newResult(c.Name(), StatusPass, "%v is on %v from %v (mounted at %v)", c.diskPath, m.FSType, m.Source, m.MountPoint)
+}
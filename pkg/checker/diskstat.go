@@ -0,0 +1,127 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const (
+	diskStatMethodStatfs = "statfs"
+	diskStatMethodStat   = "stat"
+	diskStatMethodDf     = "df"
+)
+
+// getDiskStat returns the statistics of the filesystem containing the path
+// and the method used to get them. When statfs is blocked in a restricted
+// namespace, e.g. by seccomp, it falls back to running stat and then df on
+// the host.
+func getDiskStat(host Host, path string) (*lhtypes.DiskStat, string, error) {
+	diskStat, err := host.DiskStat(path)
+	if err == nil {
+		return diskStat, diskStatMethodStatfs, nil
+	}
+	if !isStatfsRestricted(err) {
+		return nil, "", err
+	}
+
+	diskStat, statErr := getDiskStatWithStat(host, path)
+	if statErr == nil {
+		return diskStat, diskStatMethodStat, nil
+	}
+	diskStat, dfErr := getDiskStatWithDf(host, path)
+	if dfErr == nil {
+		return diskStat, diskStatMethodDf, nil
+	}
+	return nil, "", fmt.Errorf("%v, and the fallbacks failed: stat: %v, df: %v", err, statErr, dfErr)
+}
+
+// isStatfsRestricted checks whether statfs failed since it is not permitted
+// or supported rather than the path being unusable
+func isStatfsRestricted(err error) bool {
+	for _, errno := range []syscall.Errno{syscall.EPERM, syscall.EACCES, syscall.ENOSYS, syscall.EOPNOTSUPP} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeDiskStatMethod returns the note appended to the messages when the
+// statistics are not gathered with statfs
+func describeDiskStatMethod(method string) string {
+	if method == diskStatMethodStatfs {
+		return ""
+	}
+	return fmt.Sprintf(" (measured with %v since statfs is restricted)", method)
+}
+
+func getDiskStatWithStat(host Host, path string) (*lhtypes.DiskStat, error) {
+	// The filesystem type, the fundamental block size, and the total, free and
+	// available blocks
+	output, err := host.Execute("stat", []string{"-f", "-c", "%T %S %b %f %a", path}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(output)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("unexpected output %q", strings.TrimSpace(output))
+	}
+	values := make([]int64, 4)
+	for i, field := range fields[1:] {
+		if values[i], err = strconv.ParseInt(field, 10, 64); err != nil {
+			return nil, fmt.Errorf("failed to parse %q: %v", field, err)
+		}
+	}
+	blockSize, totalBlocks, freeBlocks, availableBlocks := values[0], values[1], values[2], values[3]
+
+	return &lhtypes.DiskStat{
+		Path:             path,
+		Type:             fields[0],
+		FreeBlocks:       freeBlocks,
+		TotalBlocks:      totalBlocks,
+		BlockSize:        blockSize,
+		StorageMaximum:   totalBlocks * blockSize,
+		StorageAvailable: availableBlocks * blockSize,
+	}, nil
+}
+
+func getDiskStatWithDf(host Host, path string) (*lhtypes.DiskStat, error) {
+	output, err := host.Execute("df", []string{"-P", "-B1", path}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filesystem 1-blocks Used Available Capacity Mounted-on
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(lines) < 2 || len(fields) < 6 {
+		return nil, fmt.Errorf("unexpected output %q", strings.TrimSpace(output))
+	}
+	total, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %v", fields[1], err)
+	}
+	used, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %v", fields[2], err)
+	}
+	available, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %v", fields[3], err)
+	}
+
+	return &lhtypes.DiskStat{
+		Path:             path,
+		FreeBlocks:       total - used,
+		TotalBlocks:      total,
+		BlockSize:        1,
+		StorageMaximum:   total,
+		StorageAvailable: available,
+	}, nil
+}
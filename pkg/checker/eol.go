@@ -0,0 +1,71 @@
+package checker
+
+import (
+	_ "embed"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+// eolData maps the os-release IDs and VERSION_IDs to the end-of-life dates.
+// Update eol.json when the distros publish new releases.
+//
+//go:embed eol.json
+var eolData []byte
+
+var osReleaseFieldRegexp = regexp.MustCompile(`(?m)^(\w+)=["']?(.*?)["']?$`)
+
+// EOLChecker checks that the OS release has not reached its end of life
+type EOLChecker struct {
+	host Host
+}
+
+func NewEOLChecker(options *Options) *EOLChecker {
+	return &EOLChecker{
+		host: options.Host,
+	}
+}
+
+func (c *EOLChecker) Name() string {
+	return "os-eol"
+}
+
+func (c *EOLChecker) Check() *CheckResult {
+	content, err := c.host.ReadFile(lhtypes.OsReleaseFilePath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", lhtypes.OsReleaseFilePath, err)
+	}
+
+	fields := map[string]string{}
+	for _, match := range osReleaseFieldRegexp.FindAllStringSubmatch(content, -1) {
+		fields[match[1]] = match[2]
+	}
+	id, version := fields["ID"], fields["VERSION_ID"]
+
+	table := map[string]map[string]string{}
+	if err := json.Unmarshal(eolData, &table); err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the embedded EOL table: %v", err)
+	}
+
+	eol, ok := table[id][version]
+	if !ok {
+		// e.g. RHEL 8.6 is covered by the EOL of RHEL 8
+		major, _, _ := strings.Cut(version, ".")
+		eol, ok = table[id][major]
+	}
+	if !ok {
+		return newResult(c.Name(), StatusPass, "%v %v has no known end-of-life date", id, version)
+	}
+
+	date, err := time.Parse(time.DateOnly, eol)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the end-of-life date %v of %v %v: %v", eol, id, version, err)
+	}
+	if time.Now().After(date) {
+		return newResult(c.Name(), StatusWarn, "%v %v reached its end of life on %v, upgrade to a supported release", id, version, eol)
+	}
+	return newResult(c.Name(), StatusPass, "%v %v is supported until %v", id, version, eol)
+}
@@ -0,0 +1,61 @@
+package checker
+
+import "strings"
+
+const etcPath = "/etc"
+
+// ephemeralFSTypes are the filesystem types losing the changes on reboot
+var ephemeralFSTypes = map[string]bool{
+	"tmpfs":  true,
+	"ramfs":  true,
+	"rootfs": true,
+}
+
+// EtcPersistenceChecker checks that /etc is on a persistent filesystem, since
+// on the diskless nodes booted over the network the configs written in
+// install mode vanish on reboot
+type EtcPersistenceChecker struct {
+	host Host
+}
+
+func NewEtcPersistenceChecker(options *Options) *EtcPersistenceChecker {
+	return &EtcPersistenceChecker{
+		host: options.Host,
+	}
+}
+
+func (c *EtcPersistenceChecker) Name() string {
+	return "etc-persistence"
+}
+
+func (c *EtcPersistenceChecker) Check() *CheckResult {
+	mounts, err := getMounts(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get mounts: %v", err)
+	}
+
+	m := findMount(mounts, etcPath)
+	if m == nil {
+		return newResult(c.Name(), StatusWarn, "Failed to find the mount of %v", etcPath)
+	}
+	backing := m.FSType + " from " + m.Source + " (mounted at " + m.MountPoint + ")"
+
+	if ephemeralFSTypes[m.FSType] {
+		return newResult(c.Name(), StatusWarn, "%v is on %v, the changes made in install mode do not persist across reboots", etcPath, backing)
+	}
+	if m.FSType == "overlay" {
+		// The writes of an overlay go to its upper directory
+		for _, option := range m.Options {
+			upperDir, ok := strings.CutPrefix(option, "upperdir=")
+			if !ok {
+				continue
+			}
+			if upper := findMount(mounts, upperDir); upper != nil && ephemeralFSTypes[upper.FSType] {
+				return newResult(c.Name(), StatusWarn, "%v is on %v whose upper directory %v is on %v, the changes made in install mode do not persist across reboots",
+					etcPath, backing, upperDir, upper.FSType)
+			}
+			backing += ", upper directory " + upperDir
+		}
+	}
+	return newResult(c.Name(), StatusPass, "%v is on %v", etcPath, backing)
+}
@@ -0,0 +1,38 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	lhutils "github.com/longhorn/go-common-libs/utils"
+)
+
+// LoadExceptions reads the checks to be skipped on this node from the
+// exceptions file. Each non-empty line has the format "<check-name>: <reason>",
+// and lines starting with "#" are ignored.
+func LoadExceptions(path string) (map[string]string, error) {
+	content, err := lhutils.ReadFileContent(path)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions := map[string]string{}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, reason, found := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		reason = strings.TrimSpace(reason)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid exception at %v line %d: expected \"<check-name>: <reason>\"", path, i+1)
+		}
+		if reason == "" {
+			return nil, fmt.Errorf("exception for check %v at %v line %d has no reason", name, path, i+1)
+		}
+		exceptions[name] = reason
+	}
+	return exceptions, nil
+}
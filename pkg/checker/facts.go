@@ -0,0 +1,142 @@
+package checker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+// Fact is a piece of data gathered from a node. Error is set instead of Value
+// when gathering the data failed, e.g. the file does not exist.
+type Fact struct {
+	Value string `json:"value,omitempty"`
+	Error string `json:"error,omitempty"`
+	// ExitCode is the non-zero exit code of the failed command, whose output
+	// is the value
+	ExitCode int `json:"exitCode,omitempty"`
+}
+
+// factExitError is the error of a command exiting with a non-zero code in the
+// facts
+type factExitError struct {
+	message  string
+	exitCode int
+}
+
+func (e *factExitError) Error() string {
+	return e.message
+}
+
+func (e *factExitError) ExitCode() int {
+	return e.exitCode
+}
+
+// FactsHost evaluates the checks against the facts collected separately. The
+// facts file is a JSON object mapping the fact keys to the facts, e.g.
+//
+//	{
+//	  "kernel-release": {"value": "5.15.0-91-generic"},
+//	  "file:/proc/mounts": {"value": "sysfs /sys sysfs rw 0 0\n"},
+//	  "exists:/dev/vfio/vfio": {"value": "false"},
+//	  "exec:cryptsetup --version": {"value": "cryptsetup 2.4.3"},
+//	  "exec:systemctl is-active multipathd": {"value": "inactive", "error": "exit status 3", "exitCode": 3},
+//	  "diskstat:/run": {"value": "{\"Type\": \"tmpfs\", \"StorageMaximum\": 1073741824, \"StorageAvailable\": 1073741824}"}
+//	}
+type FactsHost struct {
+	facts   map[string]Fact
+	missing map[string]bool
+}
+
+func LoadFacts(path string) (*FactsHost, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	facts := map[string]Fact{}
+	if err := json.Unmarshal(content, &facts); err != nil {
+		return nil, fmt.Errorf("failed to parse facts file %v: %v", path, err)
+	}
+
+	return &FactsHost{
+		facts:   facts,
+		missing: map[string]bool{},
+	}, nil
+}
+
+// MissingFacts returns and resets the keys of the facts requested but not
+// found since the last call
+func (h *FactsHost) MissingFacts() []string {
+	keys := []string{}
+	for key := range h.missing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h.missing = map[string]bool{}
+	return keys
+}
+
+func (h *FactsHost) get(key string) (string, error) {
+	fact, ok := h.facts[key]
+	if !ok {
+		h.missing[key] = true
+		return "", fmt.Errorf("missing fact %v", key)
+	}
+	if fact.ExitCode != 0 {
+		return fact.Value, &factExitError{message: fact.Error, exitCode: fact.ExitCode}
+	}
+	if fact.Error != "" {
+		return "", errors.New(fact.Error)
+	}
+	return fact.Value, nil
+}
+
+func (h *FactsHost) ReadFile(path string) (string, error) {
+	return h.get(fileFactKey(path))
+}
+
+func (h *FactsHost) FileExists(path string) (bool, error) {
+	value, err := h.get(existsFactKey(path))
+	if err != nil {
+		return false, err
+	}
+	return value == "true", nil
+}
+
+func (h *FactsHost) Execute(binary string, args []string, timeout time.Duration) (string, error) {
+	return h.get(execFactKey(binary, args))
+}
+
+func (h *FactsHost) KernelRelease() (string, error) {
+	return h.get(kernelReleaseFactKey)
+}
+
+func (h *FactsHost) DiskStat(path string) (*lhtypes.DiskStat, error) {
+	value, err := h.get(diskStatFactKey(path))
+	if err != nil {
+		return nil, err
+	}
+
+	diskStat := &lhtypes.DiskStat{}
+	if err := json.Unmarshal([]byte(value), diskStat); err != nil {
+		return nil, fmt.Errorf("failed to parse fact %v: %v", diskStatFactKey(path), err)
+	}
+	return diskStat, nil
+}
+
+func (h *FactsHost) Probe(key string, fn func() (string, error)) (string, error) {
+	return h.get(key)
+}
+
+func (h *FactsHost) ProbeSelf(key string, fn func() (string, error)) (string, error) {
+	return h.get(key)
+}
+
+func (h *FactsHost) WriteFile(path, data string) error {
+	return fmt.Errorf("cannot write %v when evaluating facts", path)
+}
@@ -0,0 +1,67 @@
+package checker
+
+import (
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// fileCapabilityTestDirectory is on the filesystem of the engine binaries
+// copied under /var/lib/longhorn by default
+const fileCapabilityTestDirectory = "/var/lib"
+
+// noCapabilityFilesystems are the filesystem types, as named by stat -f, that
+// do not support the security.capability xattr
+var noCapabilityFilesystems = map[string]bool{
+	"nfs":     true,
+	"cifs":    true,
+	"smb2":    true,
+	"v9fs":    true,
+	"fuseblk": true,
+	"fuse":    true,
+	"msdos":   true,
+	"vfat":    true,
+	"exfat":   true,
+}
+
+// FileCapabilityChecker checks setcap and getcap, and that the type of the
+// filesystem supports the security.capability xattr the file capabilities are
+// stored in. The package providing the tools is installed in install mode.
+type FileCapabilityChecker struct {
+	*BinaryChecker
+	host Host
+}
+
+func NewFileCapabilityChecker(options *Options) *FileCapabilityChecker {
+	return &FileCapabilityChecker{
+		BinaryChecker: NewBinaryChecker("file-capabilities", options, []string{"setcap", "getcap"}, map[types.PackageManager]string{
+			types.PackageManagerApt:    "libcap2-bin",
+			types.PackageManagerYum:    "libcap",
+			types.PackageManagerZypper: "libcap-progs",
+			types.PackageManagerApk:    "libcap-utils",
+			types.PackageManagerPacman: "libcap",
+		}),
+		host: options.Host,
+	}
+}
+
+func (c *FileCapabilityChecker) Check() *CheckResult {
+	result := c.BinaryChecker.Check()
+	if result.Status != StatusPass {
+		return result
+	}
+
+	// The filesystem type is read rather than setting a capability on a file,
+	// so that the check does not write to the host
+	output, err := c.host.Execute("stat", []string{"-f", "-c", "%T", fileCapabilityTestDirectory}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "%v, but failed to get the filesystem type of %v: %v", result.Message, fileCapabilityTestDirectory, err)
+	}
+	fsType := strings.TrimSpace(output)
+	if noCapabilityFilesystems[fsType] {
+		return newResult(c.Name(), StatusWarn, "%v, but the %v filesystem of %v does not support the file capabilities", result.Message, fsType, fileCapabilityTestDirectory)
+	}
+	return newResult(c.Name(), StatusPass, "%v, the %v filesystem of %v supports the file capabilities", result.Message, fsType, fileCapabilityTestDirectory)
+}
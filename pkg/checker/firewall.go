@@ -0,0 +1,317 @@
+package checker
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+type portRange struct {
+	from int
+	to   int
+}
+
+func (r portRange) String() string {
+	if r.from == r.to {
+		return strconv.Itoa(r.from)
+	}
+	return fmt.Sprintf("%d-%d", r.from, r.to)
+}
+
+func (r portRange) contains(port int) bool {
+	return r.from <= port && port <= r.to
+}
+
+// longhornPorts are the TCP ports the Longhorn components listen on
+var longhornPorts = map[string]portRange{
+	"nfs":                {2049, 2049},
+	"iscsi":              {3260, 3260},
+	"instance-manager":   {8500, 8501},
+	"longhorn-manager":   {9500, 9503},
+	"engine and replica": {10000, 30000},
+}
+
+// firewalldServicePorts are the ports of the firewalld services opening the
+// Longhorn ports
+var firewalldServicePorts = map[string]portRange{
+	"nfs":          {2049, 2049},
+	"iscsi-target": {3260, 3260},
+}
+
+var (
+	nftDportRegexp      = regexp.MustCompile(`dport\s+(\{[^}]*\}|\S+)`)
+	iptablesDportRegexp = regexp.MustCompile(`--dports?\s+(\S+)`)
+	nftPolicyDropRegexp = regexp.MustCompile(`hook\s+input\b.*policy\s+drop`)
+
+	// The rules qualified by the source or the input interface do not apply
+	// to all the peers, so they are ignored
+	nftQualifierRegexp      = regexp.MustCompile(`\b(saddr|iif|iifname)\b`)
+	iptablesQualifierRegexp = regexp.MustCompile(`(^|\s)(-s|--source|--src-range|-i|--in-interface)\s`)
+
+	// The rules matching nothing but TCP apply to all the ports, e.g. the
+	// final -A INPUT -j REJECT --reject-with icmp-host-prohibited
+	nftAllPortsRegexp      = regexp.MustCompile(`^((meta\s+l4proto|ip\s+protocol)\s+tcp\s+)?(counter(\s+packets\s+\d+\s+bytes\s+\d+)?\s+)?(accept|drop|reject)\b`)
+	iptablesAllPortsRegexp = regexp.MustCompile(`^-A\s+\S+(\s+-p\s+tcp)?(\s+-m\s+tcp)?(\s+-m\s+comment\s+--comment\s+("[^"]*"|\S+))?\s+-j\s+(ACCEPT|DROP|REJECT)\b`)
+)
+
+// allPorts is the port range of the rules without a port match
+var allPorts = portRange{0, 65535}
+
+// firewallRule is a rule of the input chain accepting or dropping the ports
+type firewallRule struct {
+	ports  []portRange
+	accept bool
+	text   string
+}
+
+// firewallRuleset is the input filtering of the active firewall
+type firewallRuleset struct {
+	name       string
+	policyDrop bool
+	rules      []*firewallRule
+	hint       string
+}
+
+// FirewallChecker checks whether the active firewall, firewalld, nftables or
+// iptables, blocks the ports of the Longhorn components. The ruleset is only
+// read, so the result is a hint rather than a connectivity test.
+type FirewallChecker struct {
+	host Host
+}
+
+func NewFirewallChecker(options *Options) *FirewallChecker {
+	return &FirewallChecker{
+		host: options.Host,
+	}
+}
+
+func (c *FirewallChecker) Name() string {
+	return "firewall"
+}
+
+func (c *FirewallChecker) Check() *CheckResult {
+	ruleset, err := c.getRuleset()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read the firewall ruleset: %v", err)
+	}
+	if ruleset == nil {
+		return newResult(c.Name(), StatusPass, "No active firewall detected")
+	}
+
+	blocked := []string{}
+	for name, ports := range longhornPorts {
+		if reason := ruleset.getBlockingReason(ports); reason != "" {
+			blocked = append(blocked, fmt.Sprintf("%v %v/tcp (%v)", name, ports, reason))
+		}
+	}
+	if len(blocked) > 0 {
+		sort.Strings(blocked)
+		return newResult(c.Name(), StatusWarn, "%v blocks %v; %v", ruleset.name, strings.Join(blocked, ", "), ruleset.hint)
+	}
+	return newResult(c.Name(), StatusPass, "%v is active and allows the Longhorn ports", ruleset.name)
+}
+
+// getBlockingReason returns the rule or the policy blocking any of the ports,
+// or an empty string if the ports are allowed. Each port is decided by the
+// first rule of the chain matching it, or by the policy if none does.
+func (r *firewallRuleset) getBlockingReason(ports portRange) string {
+	for port := ports.from; port <= ports.to; port++ {
+		if rule := r.getMatchingRule(port); rule != nil {
+			if !rule.accept {
+				return fmt.Sprintf("rule %q", rule.text)
+			}
+			continue
+		}
+		if r.policyDrop {
+			return "not allowed by the default drop policy"
+		}
+	}
+	return ""
+}
+
+// getMatchingRule returns the first rule of the chain matching the port, or
+// nil if there is none
+func (r *firewallRuleset) getMatchingRule(port int) *firewallRule {
+	for _, rule := range r.rules {
+		for _, p := range rule.ports {
+			if p.contains(port) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// getRuleset returns the input filtering of the active firewall, or nil if
+// there is none
+func (c *FirewallChecker) getRuleset() (*firewallRuleset, error) {
+	state, err := systemctl(c.host, "is-active", "firewalld")
+	if err != nil {
+		return nil, err
+	}
+	if state == "active" {
+		return c.getFirewalldRuleset()
+	}
+
+	if _, err := lookPath(c.host, "nft"); err == nil {
+		output, err := c.host.Execute("nft", []string{"list", "ruleset"}, lhtypes.ExecuteDefaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if ruleset := parseNftRuleset(output); ruleset != nil {
+			return ruleset, nil
+		}
+	}
+
+	if _, err := lookPath(c.host, "iptables"); err == nil {
+		output, err := c.host.Execute("iptables", []string{"-S", "INPUT"}, lhtypes.ExecuteDefaultTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return parseIptablesRuleset(output), nil
+	}
+	return nil, nil
+}
+
+// getFirewalldRuleset returns the ports opened in the default zone of
+// firewalld, which rejects the other ports
+func (c *FirewallChecker) getFirewalldRuleset() (*firewallRuleset, error) {
+	ruleset := &firewallRuleset{
+		name:       "firewalld",
+		policyDrop: true,
+		rules:      []*firewallRule{},
+		hint:       "open the ports with firewall-cmd --permanent --add-port=<port>/tcp && firewall-cmd --reload",
+	}
+
+	output, err := c.host.Execute("firewall-cmd", []string{"--list-ports"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range strings.Fields(output) {
+		port, protocol, _ := strings.Cut(field, "/")
+		if protocol != "tcp" {
+			continue
+		}
+		ruleset.rules = append(ruleset.rules, &firewallRule{ports: parsePortRanges(port), accept: true, text: field})
+	}
+
+	output, err = c.host.Execute("firewall-cmd", []string{"--list-services"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range strings.Fields(output) {
+		if ports, ok := firewalldServicePorts[service]; ok {
+			ruleset.rules = append(ruleset.rules, &firewallRule{ports: []portRange{ports}, accept: true, text: service})
+		}
+	}
+	return ruleset, nil
+}
+
+// parseNftRuleset returns the rules of the nftables input chains, or nil if
+// there is no input chain
+func parseNftRuleset(output string) *firewallRuleset {
+	ruleset := &firewallRuleset{
+		name:  "nftables",
+		rules: []*firewallRule{},
+		hint:  "add accept rules for the ports to the input chain",
+	}
+
+	found := false
+	inInput := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "chain "):
+			inInput = false
+		case strings.Contains(line, "hook input"):
+			inInput = true
+			found = true
+			if nftPolicyDropRegexp.MatchString(line) {
+				ruleset.policyDrop = true
+			}
+		case inInput:
+			if rule := parseFirewallRule(line, nftDportRegexp, nftAllPortsRegexp, nftQualifierRegexp, "accept", "drop", "reject"); rule != nil {
+				ruleset.rules = append(ruleset.rules, rule)
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return ruleset
+}
+
+// parseIptablesRuleset returns the rules of the iptables INPUT chain
+func parseIptablesRuleset(output string) *firewallRuleset {
+	ruleset := &firewallRuleset{
+		name:  "iptables",
+		rules: []*firewallRule{},
+		hint:  "add ACCEPT rules for the ports to the INPUT chain",
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "-P INPUT DROP" {
+			ruleset.policyDrop = true
+			continue
+		}
+		if rule := parseFirewallRule(line, iptablesDportRegexp, iptablesAllPortsRegexp, iptablesQualifierRegexp, "-j ACCEPT", "-j DROP", "-j REJECT"); rule != nil {
+			ruleset.rules = append(ruleset.rules, rule)
+		}
+	}
+	return ruleset
+}
+
+// parseFirewallRule returns the rule accepting or dropping the destination
+// ports in the line, or all the ports if the line matches nothing but TCP. It
+// returns nil if the line is not such a rule or is qualified by the source or
+// the input interface.
+func parseFirewallRule(line string, dportRegexp, allPortsRegexp, qualifierRegexp *regexp.Regexp, accept, drop, reject string) *firewallRule {
+	if qualifierRegexp.MatchString(line) {
+		return nil
+	}
+
+	rule := &firewallRule{ports: []portRange{allPorts}, text: line}
+	if match := dportRegexp.FindStringSubmatch(line); match != nil {
+		rule.ports = parsePortRanges(match[1])
+	} else if !allPortsRegexp.MatchString(line) {
+		return nil
+	}
+	switch {
+	case strings.Contains(line, accept):
+		rule.accept = true
+	case strings.Contains(line, drop), strings.Contains(line, reject):
+		rule.accept = false
+	default:
+		return nil
+	}
+	return rule
+}
+
+// parsePortRanges parses the ports such as 2049, 10000-30000, 10000:30000 or
+// { 2049, 3260 }
+func parsePortRanges(s string) []portRange {
+	ranges := []portRange{}
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '{' || r == '}'
+	}) {
+		from, to, found := strings.Cut(strings.ReplaceAll(field, ":", "-"), "-")
+		if !found {
+			to = from
+		}
+		f, err := strconv.Atoi(from)
+		if err != nil {
+			continue
+		}
+		t, err := strconv.Atoi(to)
+		if err != nil {
+			continue
+		}
+		ranges = append(ranges, portRange{f, t})
+	}
+	return ranges
+}
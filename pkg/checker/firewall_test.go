@@ -0,0 +1,166 @@
+package checker
+
+import "testing"
+
+func TestFirewallRuleset(t *testing.T) {
+	tests := []struct {
+		name     string
+		ruleset  *firewallRuleset
+		ports    portRange
+		expected string
+	}{
+		{
+			name:     "nftables without an input chain",
+			ruleset:  parseNftRuleset("table inet filter {\n\tchain output {\n\t\ttype filter hook output priority 0; policy accept;\n\t}\n}\n"),
+			ports:    portRange{9500, 9503},
+			expected: "",
+		},
+		{
+			name: "nftables drop policy with an accept rule",
+			ruleset: parseNftRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority 0; policy drop;
+		tcp dport 9500-9503 accept
+	}
+}`),
+			ports:    portRange{9500, 9503},
+			expected: "",
+		},
+		{
+			name: "nftables drop policy partly accepting the ports",
+			ruleset: parseNftRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority 0; policy drop;
+		tcp dport 9500 accept
+	}
+}`),
+			ports:    portRange{9500, 9503},
+			expected: "not allowed by the default drop policy",
+		},
+		{
+			name: "nftables accept rule before a drop rule",
+			ruleset: parseNftRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority 0; policy accept;
+		tcp dport 3260 accept
+		tcp dport { 2049, 3260 } drop
+	}
+}`),
+			ports:    portRange{3260, 3260},
+			expected: "",
+		},
+		{
+			name: "nftables drop rule before an accept rule",
+			ruleset: parseNftRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority 0; policy accept;
+		tcp dport { 2049, 3260 } drop
+		tcp dport 3260 accept
+	}
+}`),
+			ports:    portRange{3260, 3260},
+			expected: `rule "tcp dport { 2049, 3260 } drop"`,
+		},
+		{
+			name: "nftables rule qualified by the source is ignored",
+			ruleset: parseNftRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority 0; policy accept;
+		ip saddr 10.0.0.0/8 tcp dport 3260 drop
+	}
+}`),
+			ports:    portRange{3260, 3260},
+			expected: "",
+		},
+		{
+			name: "nftables reject rule without a port",
+			ruleset: parseNftRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority 0; policy accept;
+		ct state established,related accept
+		tcp dport 22 accept
+		reject with icmpx type admin-prohibited
+	}
+}`),
+			ports:    portRange{9500, 9503},
+			expected: `rule "reject with icmpx type admin-prohibited"`,
+		},
+		{
+			name: "nftables drop rule without a port after an accept rule",
+			ruleset: parseNftRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority 0; policy accept;
+		tcp dport 2049 accept
+		meta l4proto tcp counter packets 0 bytes 0 drop
+	}
+}`),
+			ports:    portRange{2049, 2049},
+			expected: "",
+		},
+		{
+			name: "nftables drop rule matching the connection state is ignored",
+			ruleset: parseNftRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority 0; policy accept;
+		ct state invalid drop
+	}
+}`),
+			ports:    portRange{2049, 2049},
+			expected: "",
+		},
+		{
+			name:     "iptables accept policy",
+			ruleset:  parseIptablesRuleset("-P INPUT ACCEPT\n"),
+			ports:    portRange{10000, 30000},
+			expected: "",
+		},
+		{
+			name:     "iptables drop policy with an accept rule",
+			ruleset:  parseIptablesRuleset("-P INPUT DROP\n-A INPUT -p tcp -m tcp --dport 10000:30000 -j ACCEPT\n"),
+			ports:    portRange{10000, 30000},
+			expected: "",
+		},
+		{
+			name:     "iptables reject rule",
+			ruleset:  parseIptablesRuleset("-P INPUT ACCEPT\n-A INPUT -p tcp -m multiport --dports 8500,8501 -j REJECT\n"),
+			ports:    portRange{8500, 8501},
+			expected: `rule "-A INPUT -p tcp -m multiport --dports 8500,8501 -j REJECT"`,
+		},
+		{
+			name:     "iptables reject rule without a port",
+			ruleset:  parseIptablesRuleset("-P INPUT ACCEPT\n-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT\n-A INPUT -j REJECT --reject-with icmp-host-prohibited\n"),
+			ports:    portRange{3260, 3260},
+			expected: `rule "-A INPUT -j REJECT --reject-with icmp-host-prohibited"`,
+		},
+		{
+			name:     "iptables accept rule without a port",
+			ruleset:  parseIptablesRuleset("-P INPUT DROP\n-A INPUT -p tcp -j ACCEPT\n"),
+			ports:    portRange{10000, 30000},
+			expected: "",
+		},
+		{
+			name:     "iptables drop rules matching another protocol or the state are ignored",
+			ruleset:  parseIptablesRuleset("-P INPUT ACCEPT\n-A INPUT -p udp -j DROP\n-A INPUT -m conntrack --ctstate INVALID -j DROP\n"),
+			ports:    portRange{3260, 3260},
+			expected: "",
+		},
+		{
+			name:     "iptables rule qualified by the interface is ignored",
+			ruleset:  parseIptablesRuleset("-P INPUT DROP\n-A INPUT -i eth1 -p tcp -m tcp --dport 2049 -j ACCEPT\n"),
+			ports:    portRange{2049, 2049},
+			expected: "not allowed by the default drop policy",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason := ""
+			if test.ruleset != nil {
+				reason = test.ruleset.getBlockingReason(test.ports)
+			}
+			if reason != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, reason)
+			}
+		})
+	}
+}
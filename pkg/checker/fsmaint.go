@@ -0,0 +1,140 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// fsMaintTools are the maintenance tools of a data filesystem and the
+// packages providing them
+type fsMaintTools struct {
+	binaries []string
+	packages map[types.PackageManager]string
+}
+
+func newFSMaintTools(binaries []string, pkg string) *fsMaintTools {
+	return &fsMaintTools{
+		binaries: binaries,
+		packages: map[types.PackageManager]string{
+			types.PackageManagerApt:    pkg,
+			types.PackageManagerYum:    pkg,
+			types.PackageManagerZypper: pkg,
+			types.PackageManagerApk:    pkg,
+			types.PackageManagerPacman: pkg,
+		},
+	}
+}
+
+var fsMaintToolsByFSType = map[string]*fsMaintTools{
+	"ext4": newFSMaintTools([]string{"e4defrag", "e2fsck"}, "e2fsprogs"),
+	"xfs":  newFSMaintTools([]string{"xfs_fsr", "xfs_repair"}, "xfsprogs"),
+	"btrfs": {
+		binaries: []string{"btrfs"},
+		packages: map[types.PackageManager]string{
+			types.PackageManagerApt:    "btrfs-progs",
+			types.PackageManagerYum:    "btrfs-progs",
+			types.PackageManagerZypper: "btrfsprogs",
+			types.PackageManagerApk:    "btrfs-progs",
+			types.PackageManagerPacman: "btrfs-progs",
+		},
+	},
+}
+
+// FSMaintChecker checks the defragmentation and repair tools of the
+// filesystems of the data paths, which are useful for the long-lived data
+// disks though not required, and installs them in install mode
+type FSMaintChecker struct {
+	host           Host
+	packageManager types.PackageManager
+	installer      *installer.Installer
+	diskPaths      []string
+}
+
+func NewFSMaintChecker(options *Options) *FSMaintChecker {
+	return &FSMaintChecker{
+		host:           options.Host,
+		packageManager: options.PackageManager,
+		installer:      options.Installer,
+		diskPaths:      options.DiskPaths,
+	}
+}
+
+func (c *FSMaintChecker) Name() string {
+	return "fs-maintenance-tools"
+}
+
+func (c *FSMaintChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionPackageLock, PreconditionUsrWritable}
+}
+
+// getFSTypes returns the filesystem types of the data paths having known
+// maintenance tools
+func (c *FSMaintChecker) getFSTypes() ([]string, error) {
+	mounts, err := getMounts(c.host)
+	if err != nil {
+		return nil, err
+	}
+
+	fsTypes := []string{}
+	seen := map[string]bool{}
+	for _, path := range c.diskPaths {
+		m := findMount(mounts, path)
+		if m == nil || seen[m.FSType] || fsMaintToolsByFSType[m.FSType] == nil {
+			continue
+		}
+		seen[m.FSType] = true
+		fsTypes = append(fsTypes, m.FSType)
+	}
+	sort.Strings(fsTypes)
+	return fsTypes, nil
+}
+
+func (c *FSMaintChecker) Check() *CheckResult {
+	fsTypes, err := c.getFSTypes()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get mounts: %v", err)
+	}
+	if len(fsTypes) == 0 {
+		return newResult(c.Name(), StatusSkip, "No data path is on a filesystem with known maintenance tools")
+	}
+
+	found := []string{}
+	missing := []string{}
+	for _, fsType := range fsTypes {
+		tools := fsMaintToolsByFSType[fsType]
+		for _, binary := range tools.binaries {
+			if path, err := lookPath(c.host, binary); err == nil && path != "" {
+				found = append(found, fmt.Sprintf("%v (%v)", binary, path))
+				continue
+			}
+			missing = append(missing, fmt.Sprintf("%v of %v (package %v)", binary, fsType, tools.packages[c.packageManager]))
+		}
+	}
+
+	if len(missing) > 0 {
+		return newResult(c.Name(), StatusWarn, "missing maintenance tools %v", strings.Join(missing, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "found %v for %v", strings.Join(found, ", "), strings.Join(fsTypes, ", "))
+}
+
+func (c *FSMaintChecker) Install() error {
+	fsTypes, err := c.getFSTypes()
+	if err != nil {
+		return err
+	}
+
+	for _, fsType := range fsTypes {
+		pkg, ok := fsMaintToolsByFSType[fsType].packages[c.packageManager]
+		if !ok {
+			return fmt.Errorf("no package providing the maintenance tools of %v for %v", fsType, c.packageManager)
+		}
+		if err := installPackage(c.installer, pkg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package checker
+
+import (
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const fuseDevicePath = "/dev/fuse"
+
+// FuseChecker checks that the fuse module is loadable and /dev/fuse exists for
+// the FUSE-based features, and loads the module in install mode
+type FuseChecker struct {
+	host Host
+}
+
+func NewFuseChecker(options *Options) *FuseChecker {
+	return &FuseChecker{
+		host: options.Host,
+	}
+}
+
+func (c *FuseChecker) Name() string {
+	return "fuse"
+}
+
+func (c *FuseChecker) Check() *CheckResult {
+	state, err := getModuleState(c.host, "fuse")
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the state of the fuse module: %v", err)
+	}
+	exists, err := c.host.FileExists(fuseDevicePath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "fuse module is %v, failed to check %v: %v", state, fuseDevicePath, err)
+	}
+
+	if state == moduleStateUnavailable {
+		return newResult(c.Name(), StatusWarn, "fuse module is unavailable")
+	}
+	if !exists {
+		return newResult(c.Name(), StatusWarn, "fuse module is %v, but %v does not exist", state, fuseDevicePath)
+	}
+	return newResult(c.Name(), StatusPass, "fuse module is %v, %v exists", state, fuseDevicePath)
+}
+
+func (c *FuseChecker) Remediation() *Remediation {
+	return newLoadModuleRemediation([]string{"fuse"})
+}
+
+func (c *FuseChecker) Install() error {
+	_, err := c.host.Execute("modprobe", []string{"fuse"}, lhtypes.ExecuteDefaultTimeout)
+	return err
+}
@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// targetPlaceholder replaces the target in the messages of the grouped results
+const targetPlaceholder = "<target>"
+
+// GroupResults groups the identical warnings, failures and unknown results of
+// a check against several targets into a single result listing the affected
+// targets, with the results of the targets kept as its details. The other
+// results are kept as they are, in the order of the first result of each
+// group.
+func GroupResults(results []*CheckResult) []*CheckResult {
+	groups := map[string][]*CheckResult{}
+	keys := []string{}
+	for _, result := range results {
+		key := ""
+		if result.Target != "" && statusSeverity[result.Status] > statusSeverity[StatusPass] {
+			key = fmt.Sprintf("%v\x00%v\x00%v", result.Name, result.Status, strings.ReplaceAll(result.Message, result.Target, targetPlaceholder))
+		} else {
+			// Not grouped, keyed uniquely by its position
+			key = fmt.Sprintf("\x00%d", len(keys))
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], result)
+	}
+
+	grouped := []*CheckResult{}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) == 1 {
+			grouped = append(grouped, group[0])
+			continue
+		}
+
+		targets := []string{}
+		for _, result := range group {
+			targets = append(targets, result.Target)
+		}
+		first := group[0]
+		grouped = append(grouped, &CheckResult{
+			Name:   first.Name,
+			Status: first.Status,
+			Message: fmt.Sprintf("%v (%d targets: %v)", strings.ReplaceAll(first.Message, first.Target, targetPlaceholder),
+				len(group), strings.Join(targets, ", ")),
+			Details:     group,
+			Severity:    first.Severity,
+			Remediation: first.Remediation,
+		})
+	}
+	return grouped
+}
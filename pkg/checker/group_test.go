@@ -0,0 +1,76 @@
+package checker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []*CheckResult
+		expected []*CheckResult
+	}{
+		{
+			name: "identical warnings of the targets are grouped",
+			results: []*CheckResult{
+				{Name: "disk", Status: StatusWarn, Message: "/mnt/a is full", Target: "/mnt/a"},
+				{Name: "disk", Status: StatusWarn, Message: "/mnt/b is full", Target: "/mnt/b"},
+			},
+			expected: []*CheckResult{
+				{Name: "disk", Status: StatusWarn, Message: "<target> is full (2 targets: /mnt/a, /mnt/b)", Details: []*CheckResult{
+					{Name: "disk", Status: StatusWarn, Message: "/mnt/a is full", Target: "/mnt/a"},
+					{Name: "disk", Status: StatusWarn, Message: "/mnt/b is full", Target: "/mnt/b"},
+				}},
+			},
+		},
+		{
+			name: "passing targets are not grouped",
+			results: []*CheckResult{
+				{Name: "disk", Status: StatusPass, Message: "/mnt/a is fine", Target: "/mnt/a"},
+				{Name: "disk", Status: StatusPass, Message: "/mnt/b is fine", Target: "/mnt/b"},
+			},
+			expected: []*CheckResult{
+				{Name: "disk", Status: StatusPass, Message: "/mnt/a is fine", Target: "/mnt/a"},
+				{Name: "disk", Status: StatusPass, Message: "/mnt/b is fine", Target: "/mnt/b"},
+			},
+		},
+		{
+			name: "different statuses are not grouped",
+			results: []*CheckResult{
+				{Name: "disk", Status: StatusWarn, Message: "/mnt/a is full", Target: "/mnt/a"},
+				{Name: "disk", Status: StatusFail, Message: "/mnt/b is full", Target: "/mnt/b"},
+			},
+			expected: []*CheckResult{
+				{Name: "disk", Status: StatusWarn, Message: "/mnt/a is full", Target: "/mnt/a"},
+				{Name: "disk", Status: StatusFail, Message: "/mnt/b is full", Target: "/mnt/b"},
+			},
+		},
+		{
+			name: "results without targets keep their order",
+			results: []*CheckResult{
+				{Name: "kernel", Status: StatusWarn, Message: "old"},
+				{Name: "disk", Status: StatusWarn, Message: "/mnt/a is full", Target: "/mnt/a"},
+				{Name: "kernel", Status: StatusWarn, Message: "old"},
+				{Name: "disk", Status: StatusWarn, Message: "/mnt/b is full", Target: "/mnt/b"},
+			},
+			expected: []*CheckResult{
+				{Name: "kernel", Status: StatusWarn, Message: "old"},
+				{Name: "disk", Status: StatusWarn, Message: "<target> is full (2 targets: /mnt/a, /mnt/b)", Details: []*CheckResult{
+					{Name: "disk", Status: StatusWarn, Message: "/mnt/a is full", Target: "/mnt/a"},
+					{Name: "disk", Status: StatusWarn, Message: "/mnt/b is full", Target: "/mnt/b"},
+				}},
+				{Name: "kernel", Status: StatusWarn, Message: "old"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			grouped := GroupResults(test.results)
+			if !reflect.DeepEqual(grouped, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, grouped)
+			}
+		})
+	}
+}
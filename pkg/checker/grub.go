@@ -0,0 +1,227 @@
+package checker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const grubDefaultPath = "/etc/default/grub"
+
+// grubCmdlineRegexp matches the assignments of GRUB_CMDLINE_LINUX, whose value
+// is parsed by parseGrubCmdline
+var grubCmdlineRegexp = regexp.MustCompile(`(?m)^[ \t]*GRUB_CMDLINE_LINUX=(.*)$`)
+
+// grubCmdline is the GRUB_CMDLINE_LINUX assignment in the GRUB default config
+type grubCmdline struct {
+	params []string
+	// start and end are the offsets of the value in the GRUB default config
+	start, end int
+}
+
+// parseGrubCmdline parses the GRUB_CMDLINE_LINUX assignment in the GRUB
+// default config, with the value double-quoted, single-quoted or unquoted and
+// an optional trailing comment. It returns nil if there is no assignment, and
+// an error if there are several or the value uses shell expansions, escapes or
+// other syntax that cannot be edited safely.
+func parseGrubCmdline(content string) (*grubCmdline, error) {
+	matches := grubCmdlineRegexp.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("%v has %d GRUB_CMDLINE_LINUX assignments", grubDefaultPath, len(matches))
+	}
+
+	start, end := matches[0][2], matches[0][3]
+	value, n, err := parseShellWord(content[start:end])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GRUB_CMDLINE_LINUX in %v: %w", grubDefaultPath, err)
+	}
+	if rest := strings.TrimLeft(content[start+n:end], " \t"); rest != "" && !strings.HasPrefix(rest, "#") {
+		return nil, fmt.Errorf("failed to parse GRUB_CMDLINE_LINUX in %v: unexpected %q after the value", grubDefaultPath, rest)
+	}
+	return &grubCmdline{params: strings.Fields(value), start: start, end: start + n}, nil
+}
+
+// parseShellWord parses the shell word at the beginning of s, made of
+// double-quoted, single-quoted and unquoted parts, and returns its value and
+// length. Expansions and escapes, except the ones of quoteShellWord, are not
+// supported.
+func parseShellWord(s string) (string, int, error) {
+	var value strings.Builder
+	i := 0
+	for i < len(s) {
+		switch c := s[i]; c {
+		case ' ', '\t':
+			return value.String(), i, nil
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return "", 0, fmt.Errorf("unterminated single quote")
+			}
+			value.WriteString(s[i+1 : i+1+end])
+			i += end + 2
+		case '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				switch s[i] {
+				case '\\':
+					if i+1 == len(s) || !strings.ContainsRune("\\\"$`", rune(s[i+1])) {
+						return "", 0, fmt.Errorf("unsupported escape in double quotes")
+					}
+					i++
+				case '$', '`':
+					return "", 0, fmt.Errorf("unsupported expansion %q in double quotes", s[i])
+				}
+				value.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return "", 0, fmt.Errorf("unterminated double quote")
+			}
+			i++
+		case '\\', '$', '`', ';', '&', '|', '<', '>', '(', ')':
+			return "", 0, fmt.Errorf("unsupported shell syntax %q", c)
+		default:
+			value.WriteByte(c)
+			i++
+		}
+	}
+	return value.String(), i, nil
+}
+
+// quoteShellWord double-quotes the value for a shell assignment
+func quoteShellWord(value string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	for _, c := range value {
+		if strings.ContainsRune("\\\"$`", c) {
+			quoted.WriteByte('\\')
+		}
+		quoted.WriteRune(c)
+	}
+	quoted.WriteByte('"')
+	return quoted.String()
+}
+
+// getGrubCmdline returns the kernel parameters in the GRUB default config
+func getGrubCmdline(host Host) ([]string, error) {
+	content, err := host.ReadFile(grubDefaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdline, err := parseGrubCmdline(content)
+	if err != nil {
+		return nil, err
+	}
+	if cmdline == nil {
+		return []string{}, nil
+	}
+	return cmdline.params, nil
+}
+
+// hasGrubCmdlineParams checks whether all the parameters are in the GRUB
+// default config
+func hasGrubCmdlineParams(host Host, params []string) (bool, error) {
+	cmdline, err := getGrubCmdline(host)
+	if err != nil {
+		return false, err
+	}
+
+	for _, param := range params {
+		found := false
+		for _, p := range cmdline {
+			if p == param {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// setGrubCmdlineParams sets the kernel parameters in the GRUB default config,
+// replacing the existing parameters of the same names, and regenerates the
+// GRUB config. It returns whether the GRUB default config was modified.
+func setGrubCmdlineParams(host Host, packageManager types.PackageManager, params []string) (bool, error) {
+	content, err := host.ReadFile(grubDefaultPath)
+	if err != nil {
+		return false, err
+	}
+
+	updated, modified, err := updateGrubCmdline(content, params)
+	if err != nil || !modified {
+		return false, err
+	}
+	if err := host.WriteFile(grubDefaultPath, updated); err != nil {
+		return false, err
+	}
+
+	return true, updateGrub(host, packageManager)
+}
+
+// updateGrubCmdline sets the kernel parameters in the GRUB_CMDLINE_LINUX
+// assignment of the GRUB default config content, keeping its trailing comment,
+// and adds the assignment if there is none. It refuses to modify the content
+// if the assignment cannot be parsed.
+func updateGrubCmdline(content string, params []string) (string, bool, error) {
+	parsed, err := parseGrubCmdline(content)
+	if err != nil {
+		return "", false, err
+	}
+	cmdline := []string{}
+	if parsed != nil {
+		cmdline = parsed.params
+	}
+
+	updated := []string{}
+	for _, p := range cmdline {
+		name, _, _ := strings.Cut(p, "=")
+		replaced := false
+		for _, param := range params {
+			if n, _, _ := strings.Cut(param, "="); n == name {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			updated = append(updated, p)
+		}
+	}
+	updated = append(updated, params...)
+
+	if strings.Join(updated, " ") == strings.Join(cmdline, " ") {
+		return content, false, nil
+	}
+
+	value := quoteShellWord(strings.Join(updated, " "))
+	if parsed == nil {
+		return strings.TrimRight(content, "\n") + "\nGRUB_CMDLINE_LINUX=" + value + "\n", true, nil
+	}
+	return content[:parsed.start] + value + content[parsed.end:], true, nil
+}
+
+// updateGrub regenerates the GRUB config from the GRUB default config
+func updateGrub(host Host, packageManager types.PackageManager) error {
+	var binary string
+	var args []string
+	switch packageManager {
+	case types.PackageManagerApt:
+		binary, args = "update-grub", []string{}
+	case types.PackageManagerYum, types.PackageManagerZypper:
+		binary, args = "grub2-mkconfig", []string{"-o", "/boot/grub2/grub.cfg"}
+	default:
+		return fmt.Errorf("updating GRUB config with %v is not supported", packageManager)
+	}
+
+	_, err := host.Execute(binary, args, lhtypes.ExecuteNoTimeout)
+	return err
+}
@@ -0,0 +1,134 @@
+package checker
+
+import (
+	"testing"
+)
+
+func TestUpdateGrubCmdline(t *testing.T) {
+	params := []string{"intel_iommu=on"}
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		modified bool
+		err      bool
+	}{
+		{
+			name:     "double-quoted value",
+			content:  "GRUB_TIMEOUT=5\nGRUB_CMDLINE_LINUX=\"quiet splash\"\n",
+			expected: "GRUB_TIMEOUT=5\nGRUB_CMDLINE_LINUX=\"quiet splash intel_iommu=on\"\n",
+			modified: true,
+		},
+		{
+			name:     "single-quoted value",
+			content:  "GRUB_CMDLINE_LINUX='quiet splash'\n",
+			expected: "GRUB_CMDLINE_LINUX=\"quiet splash intel_iommu=on\"\n",
+			modified: true,
+		},
+		{
+			name:     "unquoted value",
+			content:  "GRUB_CMDLINE_LINUX=quiet\n",
+			expected: "GRUB_CMDLINE_LINUX=\"quiet intel_iommu=on\"\n",
+			modified: true,
+		},
+		{
+			name:     "empty value",
+			content:  "GRUB_CMDLINE_LINUX=\n",
+			expected: "GRUB_CMDLINE_LINUX=\"intel_iommu=on\"\n",
+			modified: true,
+		},
+		{
+			name:     "concatenated quoting",
+			content:  "GRUB_CMDLINE_LINUX=\"quiet \"'splash'\n",
+			expected: "GRUB_CMDLINE_LINUX=\"quiet splash intel_iommu=on\"\n",
+			modified: true,
+		},
+		{
+			name:     "trailing comment is kept",
+			content:  "  GRUB_CMDLINE_LINUX=\"quiet\" # set by the installer\n",
+			expected: "  GRUB_CMDLINE_LINUX=\"quiet intel_iommu=on\" # set by the installer\n",
+			modified: true,
+		},
+		{
+			name:     "existing parameter is replaced",
+			content:  "GRUB_CMDLINE_LINUX='intel_iommu=off quiet'\n",
+			expected: "GRUB_CMDLINE_LINUX=\"quiet intel_iommu=on\"\n",
+			modified: true,
+		},
+		{
+			name:     "parameter already set",
+			content:  "GRUB_CMDLINE_LINUX='intel_iommu=on' # comment\n",
+			expected: "GRUB_CMDLINE_LINUX='intel_iommu=on' # comment\n",
+		},
+		{
+			name:     "commented-out assignment is ignored",
+			content:  "#GRUB_CMDLINE_LINUX=\"quiet\"\n",
+			expected: "#GRUB_CMDLINE_LINUX=\"quiet\"\nGRUB_CMDLINE_LINUX=\"intel_iommu=on\"\n",
+			modified: true,
+		},
+		{
+			name:    "expansion is refused",
+			content: "GRUB_CMDLINE_LINUX=\"$GRUB_CMDLINE_LINUX quiet\"\n",
+			err:     true,
+		},
+		{
+			name:     "escapes in double quotes",
+			content:  "GRUB_CMDLINE_LINUX=\"a=\\\"b\\\"\"\n",
+			expected: "GRUB_CMDLINE_LINUX=\"a=\\\"b\\\" intel_iommu=on\"\n",
+			modified: true,
+		},
+		{
+			name:    "escape is refused",
+			content: "GRUB_CMDLINE_LINUX=quiet\\ splash\n",
+			err:     true,
+		},
+		{
+			name:    "unterminated quote is refused",
+			content: "GRUB_CMDLINE_LINUX=\"quiet\n",
+			err:     true,
+		},
+		{
+			name:    "trailing command is refused",
+			content: "GRUB_CMDLINE_LINUX=\"quiet\" splash\n",
+			err:     true,
+		},
+		{
+			name:    "multiple assignments are refused",
+			content: "GRUB_CMDLINE_LINUX=\"quiet\"\nGRUB_CMDLINE_LINUX=\"splash\"\n",
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			updated, modified, err := updateGrubCmdline(test.content, params)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", updated)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if modified != test.modified {
+				t.Errorf("expected modified %v, got %v", test.modified, modified)
+			}
+			if updated != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, updated)
+			}
+		})
+	}
+}
+
+func TestQuoteShellWord(t *testing.T) {
+	value := `a"b$c\d` + "`e"
+	quoted := quoteShellWord(value)
+	if quoted != `"a\"b\$c\\d`+"\\`e\"" {
+		t.Fatalf("unexpected quoting %v", quoted)
+	}
+	parsed, n, err := parseShellWord(quoted)
+	if err != nil || parsed != value || n != len(quoted) {
+		t.Fatalf("failed to parse the quoted value %v back: %q, %v", quoted, parsed, err)
+	}
+}
@@ -0,0 +1,59 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// GrubHugePagesChecker checks that the HugePages for the v2 data engine are
+// reserved by the kernel parameters in the GRUB config, so they persist across
+// reboots, and adds the parameters in install mode
+type GrubHugePagesChecker struct {
+	host           Host
+	packageManager types.PackageManager
+	hugePages      int
+}
+
+func NewGrubHugePagesChecker(options *Options) *GrubHugePagesChecker {
+	return &GrubHugePagesChecker{
+		host:           options.Host,
+		packageManager: options.PackageManager,
+		hugePages:      options.HugePages,
+	}
+}
+
+func (c *GrubHugePagesChecker) Name() string {
+	return "grub-hugepages"
+}
+
+func (c *GrubHugePagesChecker) params() []string {
+	return []string{"default_hugepagesz=2M", "hugepagesz=2M", fmt.Sprintf("hugepages=%d", c.hugePages)}
+}
+
+func (c *GrubHugePagesChecker) Check() *CheckResult {
+	found, err := hasGrubCmdlineParams(c.host, c.params())
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read GRUB config: %v", err)
+	}
+	if !found {
+		return newResult(c.Name(), StatusWarn, "%v does not persist the HugePages with %q", grubDefaultPath, strings.Join(c.params(), " "))
+	}
+	return newResult(c.Name(), StatusPass, "%v persists %d HugePages of 2MiB", grubDefaultPath, c.hugePages)
+}
+
+func (c *GrubHugePagesChecker) Install() error {
+	modified, err := setGrubCmdlineParams(c.host, c.packageManager, c.params())
+	if err != nil {
+		return err
+	}
+	if modified {
+		logrus.Warnf("Modified %v to persist %d HugePages of 2MiB, reboot is required to take effect", grubDefaultPath, c.hugePages)
+	} else {
+		logrus.Infof("%v already persists %d HugePages of 2MiB", grubDefaultPath, c.hugePages)
+	}
+	return nil
+}
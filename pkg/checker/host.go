@@ -0,0 +1,133 @@
+package checker
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"time"
+
+	lhns "github.com/longhorn/go-common-libs/namespace"
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+	"github.com/longhorn/longhorn-preflight/pkg/utils"
+)
+
+// Host gathers the data evaluated by the checkers. Every piece of data is
+// identified by a fact key, so that the checks can be evaluated against the
+// facts collected separately instead of probing the live host.
+type Host interface {
+	// ReadFile returns the content of the file on the host
+	ReadFile(path string) (string, error)
+	// FileExists checks whether the path exists on the host
+	FileExists(path string) (bool, error)
+	// Execute runs the binary on the host and returns its output
+	Execute(binary string, args []string, timeout time.Duration) (string, error)
+	// KernelRelease returns the release of the running kernel
+	KernelRelease() (string, error)
+	// DiskStat returns the statistics of the filesystem containing the path
+	DiskStat(path string) (*lhtypes.DiskStat, error)
+	// Probe returns the fact gathered by fn in the host namespaces
+	Probe(key string, fn func() (string, error)) (string, error)
+	// ProbeSelf returns the fact gathered by fn in the namespaces of this
+	// process, which is used to verify the deployment of the tool itself
+	ProbeSelf(key string, fn func() (string, error)) (string, error)
+	// WriteFile writes the file on the host in install mode
+	WriteFile(path, data string) error
+}
+
+func fileFactKey(path string) string {
+	return "file:" + path
+}
+
+func existsFactKey(path string) string {
+	return "exists:" + path
+}
+
+func execFactKey(binary string, args []string) string {
+	return "exec:" + strings.Join(append([]string{binary}, args...), " ")
+}
+
+func diskStatFactKey(path string) string {
+	return "diskstat:" + path
+}
+
+const kernelReleaseFactKey = "kernel-release"
+
+// LiveHost gathers the data from the host namespaces
+type LiveHost struct {
+	procName string
+	executor *lhns.Executor
+}
+
+func NewLiveHost(procName string) *LiveHost {
+	return &LiveHost{
+		procName: procName,
+	}
+}
+
+func (h *LiveHost) ReadFile(path string) (string, error) {
+	return lhns.ReadFileContent(h.procName, path)
+}
+
+func (h *LiveHost) FileExists(path string) (bool, error) {
+	_, err := lhns.GetFileInfo(h.procName, path)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (h *LiveHost) Execute(binary string, args []string, timeout time.Duration) (string, error) {
+	if h.executor == nil {
+		executor, err := utils.NewHostExecutor()
+		if err != nil {
+			return "", err
+		}
+		h.executor = executor
+	}
+	return h.executor.Execute(binary, args, timeout)
+}
+
+func (h *LiveHost) KernelRelease() (string, error) {
+	return lhns.GetKernelRelease()
+}
+
+func (h *LiveHost) DiskStat(path string) (*lhtypes.DiskStat, error) {
+	return lhns.GetDiskStat(h.procName, path)
+}
+
+func (h *LiveHost) Probe(key string, fn func() (string, error)) (string, error) {
+	rawResult, err := lhns.RunFunc(func() (interface{}, error) {
+		return fn()
+	}, h.procName, lhtypes.HostProcDirectory, 0)
+	if err != nil {
+		return "", err
+	}
+	return rawResult.(string), nil
+}
+
+func (h *LiveHost) ProbeSelf(key string, fn func() (string, error)) (string, error) {
+	return fn()
+}
+
+func (h *LiveHost) WriteFile(path, data string) error {
+	return lhns.WriteFile(h.procName, path, data)
+}
+
+// DetectPackageManager returns the package manager of the host platform
+func DetectPackageManager(host Host) (types.PackageManager, error) {
+	content, err := host.ReadFile(lhtypes.OsReleaseFilePath)
+	if err != nil {
+		return types.PackageManagerUnknown, err
+	}
+
+	platform, err := utils.ParseOSRelease(content)
+	if err != nil {
+		return types.PackageManagerUnknown, err
+	}
+	return utils.GetPackageManager(platform)
+}
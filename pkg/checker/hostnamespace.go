@@ -0,0 +1,31 @@
+package checker
+
+const PreconditionHostNamespace = "host-namespace"
+
+// HostNamespaceChecker checks that the host namespaces can be entered, which
+// almost all the checks depend on
+type HostNamespaceChecker struct {
+	host Host
+}
+
+func NewHostNamespaceChecker(options *Options) *HostNamespaceChecker {
+	return &HostNamespaceChecker{
+		host: options.Host,
+	}
+}
+
+func (c *HostNamespaceChecker) Name() string {
+	return PreconditionHostNamespace
+}
+
+func (c *HostNamespaceChecker) Preconditions() []string {
+	return nil
+}
+
+func (c *HostNamespaceChecker) Check() *CheckResult {
+	kernelRelease, err := c.host.KernelRelease()
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to enter the host namespaces: %v", err)
+	}
+	return newResult(c.Name(), StatusPass, "entered the host namespaces, kernel %v", kernelRelease)
+}
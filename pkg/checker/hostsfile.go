@@ -0,0 +1,61 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+)
+
+const hostsFilePath = "/etc/hosts"
+
+// HostsFileChecker checks that /etc/hosts resolves the required hostnames,
+// e.g. of the registry and the control plane in the air-gapped clusters
+type HostsFileChecker struct {
+	host      Host
+	hostnames []string
+}
+
+func NewHostsFileChecker(options *Options) *HostsFileChecker {
+	return &HostsFileChecker{
+		host:      options.Host,
+		hostnames: options.RequiredHostnames,
+	}
+}
+
+func (c *HostsFileChecker) Name() string {
+	return "hosts-file"
+}
+
+func (c *HostsFileChecker) Check() *CheckResult {
+	content, err := c.host.ReadFile(hostsFilePath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", hostsFilePath, err)
+	}
+
+	addresses := map[string]string{}
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, name := range fields[1:] {
+			// The first entry of a hostname is used for resolution
+			if _, ok := addresses[strings.ToLower(name)]; !ok {
+				addresses[strings.ToLower(name)] = fields[0]
+			}
+		}
+	}
+
+	status := StatusPass
+	details := []string{}
+	for _, hostname := range c.hostnames {
+		address, ok := addresses[strings.ToLower(hostname)]
+		if !ok {
+			status = StatusWarn
+			details = append(details, fmt.Sprintf("%v: missing", hostname))
+			continue
+		}
+		details = append(details, fmt.Sprintf("%v: %v", hostname, address))
+	}
+	return newResult(c.Name(), status, "%v: %v", hostsFilePath, strings.Join(details, ", "))
+}
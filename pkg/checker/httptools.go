@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+)
+
+const httpProbeTimeoutSeconds = 10
+
+// HTTPToolsChecker checks that curl or wget exists for the operations
+// reaching the backup targets over HTTP, and probes the backup target URL if
+// given, which is left out on the air-gapped nodes. curl is installed in
+// install mode.
+type HTTPToolsChecker struct {
+	host      Host
+	installer *installer.Installer
+
+	backupTargetURL string
+}
+
+func NewHTTPToolsChecker(options *Options) *HTTPToolsChecker {
+	return &HTTPToolsChecker{
+		host:            options.Host,
+		installer:       options.Installer,
+		backupTargetURL: options.BackupTargetURL,
+	}
+}
+
+func (c *HTTPToolsChecker) Name() string {
+	return "http-tools"
+}
+
+func (c *HTTPToolsChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionPackageLock, PreconditionUsrWritable}
+}
+
+func (c *HTTPToolsChecker) Check() *CheckResult {
+	tool, path := "", ""
+	for _, binary := range []string{"curl", "wget"} {
+		if p, err := lookPath(c.host, binary); err == nil && p != "" {
+			tool, path = binary, p
+			break
+		}
+	}
+	if tool == "" {
+		return newResult(c.Name(), StatusWarn, "neither curl nor wget is found, install package curl")
+	}
+	if c.backupTargetURL == "" {
+		return newResult(c.Name(), StatusPass, "found %v (%v)", tool, path)
+	}
+
+	u, err := url.Parse(c.backupTargetURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return newResult(c.Name(), StatusWarn, "found %v (%v), cannot probe backup target %v since it is not an HTTP URL", tool, path, c.backupTargetURL)
+	}
+
+	status, err := c.probe(tool)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "found %v (%v), backup target %v is unreachable: %v", tool, path, u.Redacted(), err)
+	}
+	return newResult(c.Name(), StatusPass, "found %v (%v), backup target %v is reachable (%v)", tool, path, u.Redacted(), status)
+}
+
+// probe requests the backup target URL with the tool, and returns the
+// response status. Any response, including the authentication errors,
+// proves the target is reachable.
+func (c *HTTPToolsChecker) probe(tool string) (string, error) {
+	var script string
+	if tool == "curl" {
+		script = fmt.Sprintf(`curl -sS -o /dev/null -m %d -w 'HTTP %%{http_code}' "$1"`, httpProbeTimeoutSeconds)
+	} else {
+		// wget exits with 8 on the error responses of the server
+		script = fmt.Sprintf(`wget -q --spider -T %d -t 1 "$1"; rc=$?; if [ $rc -eq 0 ]; then echo 'HTTP 2xx'; elif [ $rc -eq 8 ]; then echo 'HTTP error response'; else exit $rc; fi`, httpProbeTimeoutSeconds)
+	}
+
+	output, err := c.host.Execute("sh", []string{"-c", script, "sh", c.backupTargetURL}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+func (c *HTTPToolsChecker) Remediation() *Remediation {
+	return newInstallPackageRemediation("curl")
+}
+
+func (c *HTTPToolsChecker) Install() error {
+	return installPackage(c.installer, "curl")
+}
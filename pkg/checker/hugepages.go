@@ -0,0 +1,124 @@
+package checker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	hugePages2MiBPath = "/sys/kernel/mm/hugepages/hugepages-2048kB"
+
+	nrHugePagesSysctl = "vm.nr_hugepages"
+)
+
+// HugePagesChecker checks that the HugePages of 2MiB required by the v2 data
+// engine are allocated, whatever the default HugePage size is, and allocates
+// them with vm.nr_hugepages in install mode if enabled
+type HugePagesChecker struct {
+	host         Host
+	hugePages    int
+	setHugePages bool
+}
+
+func NewHugePagesChecker(options *Options) *HugePagesChecker {
+	return &HugePagesChecker{
+		host:         options.Host,
+		hugePages:    options.HugePages,
+		setHugePages: options.SetHugePages,
+	}
+}
+
+func (c *HugePagesChecker) Name() string {
+	return "hugepages"
+}
+
+func (c *HugePagesChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionProcSysWritable}
+}
+
+func (c *HugePagesChecker) Check() *CheckResult {
+	exists, err := c.host.FileExists(hugePages2MiBPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to check %v: %v", hugePages2MiBPath, err)
+	}
+	if !exists {
+		return newResult(c.Name(), StatusWarn, "HugePages of 2MiB are not supported by the kernel, which are required by the v2 data engine")
+	}
+
+	allocated, err := c.readCount("nr_hugepages")
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read the allocated HugePages of 2MiB: %v", err)
+	}
+	free, err := c.readCount("free_hugepages")
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read the free HugePages of 2MiB: %v", err)
+	}
+
+	if allocated < c.hugePages {
+		return newResult(c.Name(), StatusWarn, "%d HugePages of 2MiB are allocated (%d free), fewer than %d required by the v2 data engine",
+			allocated, free, c.hugePages)
+	}
+	return newResult(c.Name(), StatusPass, "%d HugePages of 2MiB are allocated (%d free), %d required by the v2 data engine",
+		allocated, free, c.hugePages)
+}
+
+// readCount reads a counter of the HugePages of 2MiB in sysfs
+func (c *HugePagesChecker) readCount(name string) (int, error) {
+	value, err := c.host.ReadFile(hugePages2MiBPath + "/" + name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(value))
+}
+
+func (c *HugePagesChecker) Remediation() *Remediation {
+	return &Remediation{
+		Category: CategorySysctl,
+		Actions: []*RemediationAction{
+			{Type: ActionSetSysctl, Parameters: map[string]string{"key": nrHugePagesSysctl, "value": strconv.Itoa(c.hugePages)}},
+		},
+	}
+}
+
+func (c *HugePagesChecker) Install() error {
+	if !c.setHugePages {
+		return fmt.Errorf("allocating %d HugePages of 2MiB is %w", c.hugePages, errInstallDisabled)
+	}
+
+	// vm.nr_hugepages allocates the HugePages of the default size
+	size, err := c.getDefaultSize()
+	if err != nil {
+		return err
+	}
+	if size != 2048 {
+		return fmt.Errorf("default HugePage size is %dkB, allocate the HugePages of 2MiB on the kernel command line instead", size)
+	}
+
+	if err := setSysctl(c.host, nrHugePagesSysctl, strconv.Itoa(c.hugePages)); err != nil {
+		return err
+	}
+
+	// The kernel allocates fewer pages when the memory is fragmented
+	allocated, err := c.readCount("nr_hugepages")
+	if err != nil {
+		return err
+	}
+	if allocated < c.hugePages {
+		return fmt.Errorf("only %d of %d HugePages of 2MiB are allocated, the memory may be too fragmented before rebooting", allocated, c.hugePages)
+	}
+	return nil
+}
+
+// getDefaultSize returns the default HugePage size in kB
+func (c *HugePagesChecker) getDefaultSize() (int64, error) {
+	content, err := c.host.ReadFile(procMeminfoPath)
+	if err != nil {
+		return 0, err
+	}
+	size, ok := parseMeminfo(content)["Hugepagesize"]
+	if !ok {
+		return 0, fmt.Errorf("failed to find Hugepagesize in %v", procMeminfoPath)
+	}
+	return size, nil
+}
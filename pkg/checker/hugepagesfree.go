@@ -0,0 +1,70 @@
+package checker
+
+import (
+	"strconv"
+	"strings"
+)
+
+const procMeminfoPath = "/proc/meminfo"
+
+// HugePagesFreeChecker checks that the HugePages reserved for the v2 data
+// engine are still free, since other workloads may claim them even when the
+// total reservation is right, leaving none for SPDK
+type HugePagesFreeChecker struct {
+	host      Host
+	hugePages int
+}
+
+func NewHugePagesFreeChecker(options *Options) *HugePagesFreeChecker {
+	return &HugePagesFreeChecker{
+		host:      options.Host,
+		hugePages: options.HugePages,
+	}
+}
+
+func (c *HugePagesFreeChecker) Name() string {
+	return "hugepages-free"
+}
+
+func (c *HugePagesFreeChecker) Check() *CheckResult {
+	content, err := c.host.ReadFile(procMeminfoPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", procMeminfoPath, err)
+	}
+	meminfo := parseMeminfo(content)
+
+	total, free, reserved := meminfo["HugePages_Total"], meminfo["HugePages_Free"], meminfo["HugePages_Rsvd"]
+	// The reserved pages are promised to mappings but not faulted in yet
+	available := free - reserved
+
+	if total < int64(c.hugePages) {
+		return newResult(c.Name(), StatusWarn, "%d HugePages are reserved in total, fewer than %d required by the v2 data engine (%d free)",
+			total, c.hugePages, available)
+	}
+	if available < int64(c.hugePages) {
+		return newResult(c.Name(), StatusWarn, "%d of %d HugePages are free, fewer than %d required by the v2 data engine, "+
+			"other workloads may have claimed %d of them unless the v2 instance-manager already runs",
+			available, total, c.hugePages, total-available)
+	}
+	return newResult(c.Name(), StatusPass, "%d of %d HugePages are free, %d required by the v2 data engine", available, total, c.hugePages)
+}
+
+// parseMeminfo returns the values in /proc/meminfo, e.g. 1024 for
+// "HugePages_Total:    1024", in the unit of each field
+func parseMeminfo(content string) map[string]int64 {
+	meminfo := map[string]int64{}
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		if n, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+			meminfo[strings.TrimSpace(key)] = n
+		}
+	}
+	return meminfo
+}
@@ -0,0 +1,54 @@
+package checker
+
+import "strings"
+
+const hwrngCurrentPath = "/sys/class/misc/hw_random/rng_current"
+
+// rngdServices are the names of the rngd service in the distros
+var rngdServices = []string{"rngd", "rng-tools", "rng-tools-debian"}
+
+// HWRNGChecker checks that the entropy for creating many encrypted volumes is
+// backed by a hardware RNG or fed by rngd rather than only by the software
+// entropy of the kernel
+type HWRNGChecker struct {
+	host Host
+}
+
+func NewHWRNGChecker(options *Options) *HWRNGChecker {
+	return &HWRNGChecker{
+		host: options.Host,
+	}
+}
+
+func (c *HWRNGChecker) Name() string {
+	return "hwrng"
+}
+
+func (c *HWRNGChecker) Check() *CheckResult {
+	current := "none"
+	if value, err := c.host.ReadFile(hwrngCurrentPath); err == nil && strings.TrimSpace(value) != "" {
+		current = strings.TrimSpace(value)
+	}
+	if current != "none" {
+		return newResult(c.Name(), StatusPass, "Hardware RNG %v is the entropy source", current)
+	}
+
+	systemd, err := isSystemd(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to detect systemd: %v", err)
+	}
+	if systemd {
+		for _, service := range rngdServices {
+			state, err := systemctl(c.host, "is-active", service)
+			if err != nil {
+				return newResult(c.Name(), StatusWarn, "Failed to get the state of %v: %v", service, err)
+			}
+			if state == "active" {
+				return newResult(c.Name(), StatusPass, "%v feeds the entropy, no hardware RNG is available", service)
+			}
+		}
+	}
+
+	return newResult(c.Name(), StatusWarn, "Only the software entropy of the kernel is available, no hardware RNG is found in %v and rngd is not running, "+
+		"which may slow down creating many encrypted volumes", hwrngCurrentPath)
+}
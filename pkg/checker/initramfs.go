@@ -0,0 +1,114 @@
+package checker
+
+import (
+	"fmt"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const (
+	bootPath = "/boot"
+
+	PreconditionBootSpace = "boot-space"
+
+	// defaultInitramfsSize is the assumed size of the initramfs when the one
+	// of the running kernel is not found
+	defaultInitramfsSize = 128 << 20
+)
+
+// getInitramfsPaths returns the paths of the initramfs of the kernel release
+// used by the distros
+func getInitramfsPaths(kernelRelease string) []string {
+	return []string{
+		fmt.Sprintf("%v/initrd.img-%v", bootPath, kernelRelease),
+		fmt.Sprintf("%v/initramfs-%v.img", bootPath, kernelRelease),
+		fmt.Sprintf("%v/initrd-%v", bootPath, kernelRelease),
+		bootPath + "/initramfs-linux.img",
+	}
+}
+
+// BootSpaceChecker checks that /boot has space for rebuilding the initramfs
+// before the installation rebuilds it, since a full /boot leaves a truncated
+// initramfs that fails to boot
+type BootSpaceChecker struct {
+	host Host
+}
+
+func NewBootSpaceChecker(options *Options) *BootSpaceChecker {
+	return &BootSpaceChecker{
+		host: options.Host,
+	}
+}
+
+func (c *BootSpaceChecker) Name() string {
+	return PreconditionBootSpace
+}
+
+func (c *BootSpaceChecker) Check() *CheckResult {
+	diskStat, method, err := getDiskStat(c.host, bootPath)
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to get the free space of %v: %v", bootPath, err)
+	}
+
+	required, source, err := c.getRequiredSpace()
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to get the size of the initramfs: %v", err)
+	}
+	if diskStat.StorageAvailable < required {
+		return newResult(c.Name(), StatusFail, "%v has %v free, rebuilding the initramfs requires %v (%v), free up space before the installation%v",
+			bootPath, formatBytes(diskStat.StorageAvailable), formatBytes(required), source, describeDiskStatMethod(method))
+	}
+	return newResult(c.Name(), StatusPass, "%v has %v free, rebuilding the initramfs requires %v (%v)%v",
+		bootPath, formatBytes(diskStat.StorageAvailable), formatBytes(required), source, describeDiskStatMethod(method))
+}
+
+// getRequiredSpace returns the space for writing the new initramfs next to
+// the current one, with a margin for the added modules
+func (c *BootSpaceChecker) getRequiredSpace() (int64, string, error) {
+	kernelRelease, err := c.host.KernelRelease()
+	if err != nil {
+		return 0, "", err
+	}
+
+	for _, path := range getInitramfsPaths(kernelRelease) {
+		exists, err := c.host.FileExists(path)
+		if err != nil {
+			return 0, "", err
+		}
+		if !exists {
+			continue
+		}
+		output, err := c.host.Execute("stat", []string{"-c", "%s", path}, lhtypes.ExecuteDefaultTimeout)
+		if err != nil {
+			return 0, "", err
+		}
+		var size int64
+		if _, err := fmt.Sscan(output, &size); err != nil {
+			return 0, "", fmt.Errorf("failed to parse the size of %v: %v", path, err)
+		}
+		return size + size/5, fmt.Sprintf("size of %v plus 20%%", path), nil
+	}
+	return defaultInitramfsSize, "initramfs of the running kernel not found", nil
+}
+
+// RebuildInitramfs regenerates the initramfs of the running kernel with the
+// tool of the distro, so it contains the persisted modules and configs
+func RebuildInitramfs(host Host, packageManager types.PackageManager) error {
+	var binary string
+	var args []string
+	switch packageManager {
+	case types.PackageManagerApt:
+		binary, args = "update-initramfs", []string{"-u"}
+	case types.PackageManagerYum, types.PackageManagerZypper:
+		binary, args = "dracut", []string{"--force"}
+	case types.PackageManagerPacman:
+		binary, args = "mkinitcpio", []string{"-P"}
+	default:
+		return fmt.Errorf("rebuilding the initramfs with %v is not supported", packageManager)
+	}
+
+	_, err := host.Execute(binary, args, lhtypes.ExecuteNoTimeout)
+	return err
+}
@@ -0,0 +1,110 @@
+package checker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	inotifyMaxUserWatchesSysctl   = "fs.inotify.max_user_watches"
+	inotifyMaxUserInstancesSysctl = "fs.inotify.max_user_instances"
+)
+
+// InotifyChecker checks that the inotify limits leave room for the watchers
+// of Longhorn, the CSI components and the monitoring, which otherwise stall
+// silently, and raises and persists them in install mode
+type InotifyChecker struct {
+	host    Host
+	minimum map[string]int
+}
+
+func NewInotifyChecker(options *Options) *InotifyChecker {
+	return &InotifyChecker{
+		host: options.Host,
+		minimum: map[string]int{
+			inotifyMaxUserWatchesSysctl:   options.MinInotifyWatches,
+			inotifyMaxUserInstancesSysctl: options.MinInotifyInstances,
+		},
+	}
+}
+
+func (c *InotifyChecker) Name() string {
+	return "inotify-limits"
+}
+
+func (c *InotifyChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionProcSysWritable}
+}
+
+func (c *InotifyChecker) keys() []string {
+	return []string{inotifyMaxUserWatchesSysctl, inotifyMaxUserInstancesSysctl}
+}
+
+// getLowKeys returns the current values of the limits, and the limits lower
+// than the minimums
+func (c *InotifyChecker) getLowKeys() ([]string, []string, error) {
+	values := []string{}
+	low := []string{}
+	for _, key := range c.keys() {
+		value, err := readSysctl(c.host, key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %v: %v", key, err)
+		}
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %v %q: %v", key, value, err)
+		}
+		values = append(values, fmt.Sprintf("%v is %d", key, limit))
+		if limit < c.minimum[key] {
+			low = append(low, key)
+		}
+	}
+	return values, low, nil
+}
+
+func (c *InotifyChecker) Check() *CheckResult {
+	values, low, err := c.getLowKeys()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "%v", err)
+	}
+
+	if len(low) > 0 {
+		minimums := []string{}
+		for _, key := range low {
+			minimums = append(minimums, fmt.Sprintf("%v %d", key, c.minimum[key]))
+		}
+		return newResult(c.Name(), StatusWarn, "%v, lower than %v", strings.Join(values, ", "), strings.Join(minimums, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "%v", strings.Join(values, ", "))
+}
+
+// Remediation raises the limits lower than the minimums, or all of them if
+// they cannot be read
+func (c *InotifyChecker) Remediation() *Remediation {
+	_, low, err := c.getLowKeys()
+	if err != nil {
+		low = c.keys()
+	}
+	actions := []*RemediationAction{}
+	for _, key := range low {
+		actions = append(actions, &RemediationAction{Type: ActionSetSysctl, Parameters: map[string]string{"key": key, "value": strconv.Itoa(c.minimum[key])}})
+	}
+	return &Remediation{
+		Category: CategorySysctl,
+		Actions:  actions,
+	}
+}
+
+func (c *InotifyChecker) Install() error {
+	_, low, err := c.getLowKeys()
+	if err != nil {
+		return err
+	}
+	for _, key := range low {
+		if err := setSysctl(c.host, key, strconv.Itoa(c.minimum[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
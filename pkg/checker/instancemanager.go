@@ -0,0 +1,157 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// processInstanceManager is the name of the longhorn-instance-manager process,
+// truncated to 15 characters by the kernel
+const processInstanceManager = "longhorn-instan"
+
+var (
+	containerIDRegexp = regexp.MustCompile(`[0-9a-f]{64}`)
+
+	// containerImageAnnotations are the annotations of the image name in the
+	// OCI configs written by containerd and CRI-O
+	containerImageAnnotations = []string{"io.kubernetes.cri.image-name", "io.kubernetes.cri-o.ImageName"}
+)
+
+// InstanceManagerChecker checks whether an instance-manager of another
+// Longhorn release is still running on the node, which conflicts with the
+// reinstalled or upgraded release. The versions are only compared when the
+// release is given with --longhorn-version.
+type InstanceManagerChecker struct {
+	host    Host
+	version string
+}
+
+func NewInstanceManagerChecker(options *Options) *InstanceManagerChecker {
+	c := &InstanceManagerChecker{
+		host: options.Host,
+	}
+	if options.LonghornVersionSet {
+		c.version = options.Requirements.Version
+	}
+	return c
+}
+
+func (c *InstanceManagerChecker) Name() string {
+	return "instance-manager"
+}
+
+func (c *InstanceManagerChecker) Check() *CheckResult {
+	value, err := c.host.ProbeSelf("process-pids:"+processInstanceManager, func() (string, error) {
+		pids, err := findProcesses(processInstanceManager)
+		if err != nil {
+			return "", err
+		}
+		values := []string{}
+		for _, pid := range pids {
+			values = append(values, fmt.Sprint(pid))
+		}
+		return strings.Join(values, " "), nil
+	})
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to find the instance-manager processes: %v", err)
+	}
+
+	pids := strings.Fields(value)
+	if len(pids) == 0 {
+		return newResult(c.Name(), StatusPass, "No instance-manager is running")
+	}
+
+	status := StatusPass
+	processes := []string{}
+	for _, pid := range pids {
+		version, err := c.getVersion(pid)
+		if err != nil {
+			if c.version != "" {
+				status = worstStatus(status, StatusWarn)
+			}
+			processes = append(processes, fmt.Sprintf("PID %v (unknown version: %v)", pid, err))
+			continue
+		}
+		if c.version != "" && !isSameMinorVersion(version, c.version) {
+			status = worstStatus(status, StatusWarn)
+		}
+		processes = append(processes, fmt.Sprintf("PID %v (%v)", pid, version))
+	}
+
+	switch {
+	case status != StatusPass:
+		return newResult(c.Name(), status, "instance-manager of a version other than %v may be running, which conflicts with the installation: %v",
+			c.version, strings.Join(processes, ", "))
+	case c.version != "":
+		return newResult(c.Name(), StatusPass, "instance-manager of %v is running: %v", c.version, strings.Join(processes, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "instance-manager is running: %v", strings.Join(processes, ", "))
+}
+
+// getVersion returns the version in the image tag of the container of the
+// process, found by the container ID in its cgroup
+func (c *InstanceManagerChecker) getVersion(pid string) (string, error) {
+	cgroup, err := c.host.ReadFile(fmt.Sprintf("/proc/%v/cgroup", pid))
+	if err != nil {
+		return "", err
+	}
+	ids := containerIDRegexp.FindAllString(cgroup, -1)
+	if len(ids) == 0 {
+		return "", fmt.Errorf("no container ID in the cgroup")
+	}
+	id := ids[len(ids)-1]
+
+	image, err := c.getContainerImage(id)
+	if err != nil {
+		return "", err
+	}
+	// The tag follows the last colon after the registry and the repository
+	tag := image[strings.LastIndex(image, "/")+1:]
+	if _, t, found := strings.Cut(tag, ":"); found && parseVersion(t) != "" {
+		return t, nil
+	}
+	return "", fmt.Errorf("no version in the tag of image %v", image)
+}
+
+// getContainerImage returns the image of the container from the annotations
+// of its OCI config written by containerd or CRI-O
+func (c *InstanceManagerChecker) getContainerImage(id string) (string, error) {
+	for _, path := range []string{
+		fmt.Sprintf("/run/containerd/io.containerd.runtime.v2.task/k8s.io/%v/config.json", id),
+		fmt.Sprintf("/run/containers/storage/overlay-containers/%v/userdata/config.json", id),
+	} {
+		exists, err := c.host.FileExists(path)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			continue
+		}
+		content, err := c.host.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		var config struct {
+			Annotations map[string]string `json:"annotations"`
+		}
+		if err := json.Unmarshal([]byte(content), &config); err != nil {
+			return "", fmt.Errorf("failed to parse %v: %v", path, err)
+		}
+		for _, key := range containerImageAnnotations {
+			if image := config.Annotations[key]; image != "" {
+				return image, nil
+			}
+		}
+		return "", fmt.Errorf("no image annotation in %v", path)
+	}
+	return "", fmt.Errorf("no OCI config of container %v", id)
+}
+
+// isSameMinorVersion checks whether the version, e.g. v1.6.2, belongs to the
+// minor release, e.g. v1.6
+func isSameMinorVersion(version, minor string) bool {
+	parts := strings.Split(parseVersion(version), ".")
+	return len(parts) >= 2 && "v"+parts[0]+"."+parts[1] == minor
+}
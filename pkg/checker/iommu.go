@@ -0,0 +1,123 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const (
+	procCmdlinePath = "/proc/cmdline"
+	procCPUInfoPath = "/proc/cpuinfo"
+)
+
+var iommuCmdlineParams = map[string]string{
+	"GenuineIntel": "intel_iommu=on",
+	"AuthenticAMD": "amd_iommu=on",
+}
+
+// IOMMUChecker checks that the IOMMU is enabled on the kernel command line
+// when the NVMe devices are bound to vfio-pci for the v2 data engine, and adds
+// the kernel parameter to the GRUB config in install mode if enabled
+type IOMMUChecker struct {
+	host           Host
+	packageManager types.PackageManager
+	persistIOMMU   bool
+}
+
+func NewIOMMUChecker(options *Options) *IOMMUChecker {
+	return &IOMMUChecker{
+		host:           options.Host,
+		packageManager: options.PackageManager,
+		persistIOMMU:   options.PersistIOMMU,
+	}
+}
+
+func (c *IOMMUChecker) Name() string {
+	return "iommu"
+}
+
+func (c *IOMMUChecker) Check() *CheckResult {
+	cmdline, err := c.host.ReadFile(procCmdlinePath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", procCmdlinePath, err)
+	}
+
+	tokens := []string{}
+	enabled := false
+	for _, token := range strings.Fields(cmdline) {
+		if !strings.HasPrefix(token, "intel_iommu=") && !strings.HasPrefix(token, "amd_iommu=") && !strings.HasPrefix(token, "iommu=") {
+			continue
+		}
+		tokens = append(tokens, token)
+		if token == "intel_iommu=on" || token == "amd_iommu=on" {
+			enabled = true
+		}
+	}
+
+	if !enabled {
+		if len(tokens) == 0 {
+			return newResult(c.Name(), StatusWarn, "IOMMU is not enabled on the kernel command line, which is required by %v", driverVFIO)
+		}
+		return newResult(c.Name(), StatusWarn, "IOMMU is not enabled on the kernel command line (%v), which is required by %v", strings.Join(tokens, " "), driverVFIO)
+	}
+	return newResult(c.Name(), StatusPass, "IOMMU is enabled on the kernel command line (%v)", strings.Join(tokens, " "))
+}
+
+func (c *IOMMUChecker) Install() error {
+	if !c.persistIOMMU {
+		return fmt.Errorf("enabling the IOMMU in the GRUB config is %w", errInstallDisabled)
+	}
+
+	param, err := c.getCmdlineParam()
+	if err != nil {
+		return err
+	}
+
+	modified, err := setGrubCmdlineParams(c.host, c.packageManager, []string{param})
+	if err != nil {
+		return err
+	}
+	if modified {
+		logrus.Warnf("Modified %v to enable the IOMMU with %v, reboot is required to take effect", grubDefaultPath, param)
+	} else {
+		logrus.Infof("%v already enables the IOMMU with %v, reboot is required to take effect", grubDefaultPath, param)
+	}
+	return nil
+}
+
+// IsRebootRequired checks whether the GRUB config already enables the IOMMU,
+// which takes effect after rebooting
+func (c *IOMMUChecker) IsRebootRequired() bool {
+	param, err := c.getCmdlineParam()
+	if err != nil {
+		return false
+	}
+	found, err := hasGrubCmdlineParams(c.host, []string{param})
+	return err == nil && found
+}
+
+// getCmdlineParam returns the kernel parameter enabling the IOMMU of the CPU vendor
+func (c *IOMMUChecker) getCmdlineParam() (string, error) {
+	cpuInfo, err := c.host.ReadFile(procCPUInfoPath)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(cpuInfo, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "vendor_id" {
+			continue
+		}
+		vendor := strings.TrimSpace(value)
+		param, ok := iommuCmdlineParams[vendor]
+		if !ok {
+			return "", fmt.Errorf("unsupported CPU vendor %v for enabling the IOMMU", vendor)
+		}
+		return param, nil
+	}
+	return "", fmt.Errorf("failed to find the CPU vendor in %v", procCPUInfoPath)
+}
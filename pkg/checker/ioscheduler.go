@@ -0,0 +1,105 @@
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const ioSchedulerUdevRulePath = "/etc/udev/rules.d/60-longhorn-io-scheduler.rules"
+
+var activeIOSchedulerRegexp = regexp.MustCompile(`\[(.+?)\]`)
+
+// IOSchedulerChecker checks that the I/O scheduler of the data device suits
+// the device type, and sets it with a udev rule in install mode if enabled
+type IOSchedulerChecker struct {
+	host           Host
+	device         string
+	setIOScheduler bool
+}
+
+func NewIOSchedulerChecker(options *Options) *IOSchedulerChecker {
+	return &IOSchedulerChecker{
+		host:           options.Host,
+		device:         filepath.Base(options.DataDevice),
+		setIOScheduler: options.SetIOScheduler,
+	}
+}
+
+func (c *IOSchedulerChecker) Name() string {
+	return "io-scheduler"
+}
+
+func (c *IOSchedulerChecker) Check() *CheckResult {
+	scheduler, err := c.getIOScheduler()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the I/O scheduler of %v: %v", c.device, err)
+	}
+
+	deviceType, recommended, err := c.getRecommendedIOSchedulers()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the type of %v: %v", c.device, err)
+	}
+
+	for _, r := range recommended {
+		if scheduler == r {
+			return newResult(c.Name(), StatusPass, "%v device %v uses I/O scheduler %v", deviceType, c.device, scheduler)
+		}
+	}
+	return newResult(c.Name(), StatusWarn, "%v device %v uses I/O scheduler %v, recommended: %v",
+		deviceType, c.device, scheduler, strings.Join(recommended, " or "))
+}
+
+func (c *IOSchedulerChecker) Install() error {
+	if !c.setIOScheduler {
+		return fmt.Errorf("setting the I/O scheduler of %v is %w", c.device, errInstallDisabled)
+	}
+
+	_, recommended, err := c.getRecommendedIOSchedulers()
+	if err != nil {
+		return err
+	}
+
+	rule := fmt.Sprintf("ACTION==\"add|change\", KERNEL==\"%v\", ATTR{queue/scheduler}=\"%v\"\n", c.device, recommended[0])
+	if err := c.host.WriteFile(ioSchedulerUdevRulePath, rule); err != nil {
+		return err
+	}
+
+	// Apply the scheduler right away instead of waiting for the udev rule on the next boot
+	return c.host.WriteFile(c.sysfsPath("scheduler"), recommended[0])
+}
+
+func (c *IOSchedulerChecker) sysfsPath(name string) string {
+	return filepath.Join("/sys/block", c.device, "queue", name)
+}
+
+func (c *IOSchedulerChecker) getIOScheduler() (string, error) {
+	content, err := c.host.ReadFile(c.sysfsPath("scheduler"))
+	if err != nil {
+		return "", err
+	}
+
+	match := activeIOSchedulerRegexp.FindStringSubmatch(content)
+	if match == nil {
+		return strings.TrimSpace(content), nil
+	}
+	return match[1], nil
+}
+
+// getRecommendedIOSchedulers returns the device type and its recommended I/O
+// schedulers, the preferred one first
+func (c *IOSchedulerChecker) getRecommendedIOSchedulers() (string, []string, error) {
+	if strings.HasPrefix(c.device, "nvme") {
+		return "NVMe", []string{"none", "mq-deadline"}, nil
+	}
+
+	rotational, err := c.host.ReadFile(c.sysfsPath("rotational"))
+	if err != nil {
+		return "", nil, err
+	}
+	if strings.TrimSpace(rotational) == "1" {
+		return "rotational", []string{"mq-deadline", "bfq"}, nil
+	}
+	return "SSD", []string{"none", "mq-deadline"}, nil
+}
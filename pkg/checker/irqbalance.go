@@ -0,0 +1,78 @@
+package checker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const (
+	processIrqbalance = "irqbalance"
+
+	// irqbalanceMinCPUs is the number of CPUs from which the IRQs of a single
+	// CPU bottleneck the storage throughput
+	irqbalanceMinCPUs = 8
+)
+
+// IrqbalanceChecker checks whether irqbalance distributes the IRQs of the
+// storage and network devices on a node with many CPUs
+type IrqbalanceChecker struct {
+	host Host
+}
+
+func NewIrqbalanceChecker(options *Options) *IrqbalanceChecker {
+	return &IrqbalanceChecker{
+		host: options.Host,
+	}
+}
+
+func (c *IrqbalanceChecker) Name() string {
+	return "irqbalance"
+}
+
+func (c *IrqbalanceChecker) Check() *CheckResult {
+	output, err := c.host.Execute("getconf", []string{"_NPROCESSORS_ONLN"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the number of CPUs: %v", err)
+	}
+	cpus, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the number of CPUs %q: %v", output, err)
+	}
+
+	state, err := c.getState()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the state of irqbalance: %v", err)
+	}
+
+	if state != "active" && cpus >= irqbalanceMinCPUs {
+		return newResult(c.Name(), StatusWarn, "irqbalance is %v on a node with %d CPUs, the IRQs of the storage and network devices may bottleneck on few CPUs", state, cpus)
+	}
+	return newResult(c.Name(), StatusPass, "irqbalance is %v on a node with %d CPUs", state, cpus)
+}
+
+// getState returns the state of the irqbalance service, or whether the
+// process is running on the hosts without systemd
+func (c *IrqbalanceChecker) getState() (string, error) {
+	systemd, err := isSystemd(c.host)
+	if err != nil {
+		return "", err
+	}
+	if systemd {
+		return systemctl(c.host, "is-active", processIrqbalance)
+	}
+
+	running, err := c.host.ProbeSelf("process-running:"+processIrqbalance, func() (string, error) {
+		pids, err := findProcesses(processIrqbalance)
+		return fmt.Sprint(len(pids) > 0), err
+	})
+	if err != nil {
+		return "", err
+	}
+	if running == "true" {
+		return "active", nil
+	}
+	return "not running", nil
+}
@@ -0,0 +1,73 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+)
+
+const processIscsid = "iscsid"
+
+// IscsidLocationChecker checks that iscsid runs on the host rather than in a
+// container, since Longhorn expects the host daemon
+type IscsidLocationChecker struct {
+	host Host
+}
+
+func NewIscsidLocationChecker(options *Options) *IscsidLocationChecker {
+	return &IscsidLocationChecker{
+		host: options.Host,
+	}
+}
+
+func (c *IscsidLocationChecker) Name() string {
+	return "iscsid-location"
+}
+
+// Preconditions returns none since the processes are inspected from the host
+// proc directory mounted in the container
+func (c *IscsidLocationChecker) Preconditions() []string {
+	return nil
+}
+
+func (c *IscsidLocationChecker) Check() *CheckResult {
+	value, err := c.host.ProbeSelf("process-location:"+processIscsid, func() (string, error) {
+		return getProcessLocations(processIscsid)
+	})
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to find %v: %v", processIscsid, err)
+	}
+
+	locations := strings.Fields(value)
+	if len(locations) == 0 {
+		return newResult(c.Name(), StatusWarn, "%v is not running", processIscsid)
+	}
+	for _, location := range locations {
+		if strings.HasSuffix(location, ":host") {
+			return newResult(c.Name(), StatusPass, "%v is running on the host (%v)", processIscsid, strings.Join(locations, ", "))
+		}
+	}
+	return newResult(c.Name(), StatusWarn, "%v is running in a container instead of on the host (%v)", processIscsid, strings.Join(locations, ", "))
+}
+
+// getProcessLocations returns whether each process of the name runs on the
+// host or in a container, e.g. "1234:host 5678:container"
+func getProcessLocations(name string) (string, error) {
+	pids, err := findProcesses(name)
+	if err != nil {
+		return "", err
+	}
+
+	locations := []string{}
+	for _, pid := range pids {
+		onHost, err := isInHostMountNamespace(pid)
+		if err != nil {
+			return "", err
+		}
+		location := "container"
+		if onHost {
+			location = "host"
+		}
+		locations = append(locations, fmt.Sprintf("%d:%v", pid, location))
+	}
+	return strings.Join(locations, " "), nil
+}
@@ -0,0 +1,130 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// iscsiPackages are the full iSCSI initiator packages required by Longhorn,
+// which some distros split from the minimal ones
+var iscsiPackages = map[types.PackageManager]string{
+	types.PackageManagerApt:    "open-iscsi",
+	types.PackageManagerYum:    "iscsi-initiator-utils",
+	types.PackageManagerZypper: "open-iscsi",
+}
+
+// IscsiPackageChecker checks that iscsid and iscsiadm are provided by the full
+// iSCSI initiator package rather than by any package named after iSCSI, and
+// installs the full package in install mode
+type IscsiPackageChecker struct {
+	host           Host
+	packageManager types.PackageManager
+	installer      *installer.Installer
+
+	binaries []string
+}
+
+func NewIscsiPackageChecker(options *Options) *IscsiPackageChecker {
+	return &IscsiPackageChecker{
+		host:           options.Host,
+		packageManager: options.PackageManager,
+		installer:      options.Installer,
+		binaries:       []string{processIscsid, "iscsiadm"},
+	}
+}
+
+func (c *IscsiPackageChecker) Name() string {
+	return "iscsi-package"
+}
+
+func (c *IscsiPackageChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionPackageLock, PreconditionUsrWritable}
+}
+
+func (c *IscsiPackageChecker) Check() *CheckResult {
+	expected, ok := iscsiPackages[c.packageManager]
+	if !ok {
+		return newResult(c.Name(), StatusSkip, "Querying the package ownership with %v is not supported", c.packageManager)
+	}
+
+	owners := map[string][]string{}
+	for _, binary := range c.binaries {
+		path, err := lookPath(c.host, binary)
+		if err != nil || path == "" {
+			return newResult(c.Name(), StatusWarn, "missing %v, install package %v", binary, expected)
+		}
+		pkg, err := c.getOwner(binary, path)
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to find the package providing %v: %v", path, err)
+		}
+		owners[pkg] = append(owners[pkg], path)
+	}
+
+	provided := []string{}
+	unexpected := false
+	for pkg, paths := range owners {
+		provided = append(provided, fmt.Sprintf("%v by %v", strings.Join(paths, ", "), pkg))
+		if pkg != expected {
+			unexpected = true
+		}
+	}
+	sort.Strings(provided)
+
+	if unexpected {
+		return newResult(c.Name(), StatusWarn, "%v provided instead of %v required by Longhorn, install package %v",
+			strings.Join(provided, "; "), expected, expected)
+	}
+	return newResult(c.Name(), StatusPass, "%v provided", strings.Join(provided, "; "))
+}
+
+// getOwner returns the name of the package owning the binary
+func (c *IscsiPackageChecker) getOwner(binary, path string) (string, error) {
+	var binaryName string
+	var args []string
+	switch c.packageManager {
+	case types.PackageManagerApt:
+		// The pattern matches the binary wherever dpkg recorded it, e.g. in /sbin
+		// on the hosts with merged /usr
+		binaryName, args = "dpkg", []string{"-S", "*bin/" + binary}
+	default:
+		binaryName, args = "rpm", []string{"-qf", "--qf", `%{NAME}\n`, path}
+	}
+
+	output, err := c.host.Execute(binaryName, args, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(output), "\n", 2)[0])
+	if c.packageManager == types.PackageManagerApt {
+		// e.g. "open-iscsi: /usr/sbin/iscsiadm", where the package may carry
+		// the architecture
+		line = strings.SplitN(strings.SplitN(line, ": ", 2)[0], ":", 2)[0]
+	}
+	if line == "" {
+		return "", fmt.Errorf("no package owns %v", path)
+	}
+	return line, nil
+}
+
+func (c *IscsiPackageChecker) Install() error {
+	pkg, ok := iscsiPackages[c.packageManager]
+	if !ok {
+		return fmt.Errorf("no iSCSI initiator package for %v", c.packageManager)
+	}
+
+	return installPackage(c.installer, pkg)
+}
+
+func (c *IscsiPackageChecker) Remediation() *Remediation {
+	pkg, ok := iscsiPackages[c.packageManager]
+	if !ok {
+		return nil
+	}
+	return newInstallPackageRemediation(pkg)
+}
@@ -0,0 +1,125 @@
+package checker
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const iscsidConfPath = "/etc/iscsi/iscsid.conf"
+
+// defaultIscsiConnTimeouts are the defaults of open-iscsi, which apply when
+// the settings are commented out in iscsid.conf
+var defaultIscsiConnTimeouts = map[string]string{
+	"node.conn[0].timeo.login_timeout":     "15",
+	"node.conn[0].timeo.logout_timeout":    "15",
+	"node.conn[0].timeo.noop_out_interval": "5",
+	"node.conn[0].timeo.noop_out_timeout":  "5",
+}
+
+// IscsiTimeoutChecker checks the connection timeouts of the iSCSI sessions in
+// iscsid.conf, which decide how Longhorn reattaches the volumes after network
+// blips, and sets the recommended values and restarts iscsid in install mode
+type IscsiTimeoutChecker struct {
+	host        Host
+	recommended map[string]string
+}
+
+func NewIscsiTimeoutChecker(options *Options) *IscsiTimeoutChecker {
+	return &IscsiTimeoutChecker{
+		host:        options.Host,
+		recommended: options.Config.Iscsi.ConnTimeouts,
+	}
+}
+
+func (c *IscsiTimeoutChecker) Name() string {
+	return "iscsi-timeouts"
+}
+
+func (c *IscsiTimeoutChecker) keys() []string {
+	keys := []string{}
+	for key := range c.recommended {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *IscsiTimeoutChecker) Check() *CheckResult {
+	content, err := c.host.ReadFile(iscsidConfPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", iscsidConfPath, err)
+	}
+
+	current := []string{}
+	deviations := []string{}
+	for _, key := range c.keys() {
+		value := getIscsidSetting(content, key)
+		current = append(current, fmt.Sprintf("%v=%v", key, value))
+		if value != c.recommended[key] {
+			deviations = append(deviations, fmt.Sprintf("%v=%v (recommended %v)", key, value, c.recommended[key]))
+		}
+	}
+
+	if len(deviations) > 0 {
+		return newResult(c.Name(), StatusWarn, "%v deviates from the recommended timeouts: %v", iscsidConfPath, strings.Join(deviations, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "%v", strings.Join(current, ", "))
+}
+
+func (c *IscsiTimeoutChecker) Install() error {
+	content, err := c.host.ReadFile(iscsidConfPath)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range c.keys() {
+		content = setIscsidSetting(content, key, c.recommended[key])
+	}
+	if err := c.host.WriteFile(iscsidConfPath, content); err != nil {
+		return err
+	}
+
+	systemd, err := isSystemd(c.host)
+	if err != nil {
+		return err
+	}
+	if !systemd {
+		logrus.Warnf("Modified %v, restart iscsid to take effect", iscsidConfPath)
+		return nil
+	}
+	_, err = c.host.Execute("systemctl", []string{"try-restart", "iscsid.service"}, lhtypes.ExecuteDefaultTimeout)
+	return err
+}
+
+func iscsidSettingRegexp(key string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(key) + `\s*=\s*(\S*)\s*$`)
+}
+
+// getIscsidSetting returns the value of the setting in iscsid.conf, or the
+// default of open-iscsi if it is not set
+func getIscsidSetting(content, key string) string {
+	if match := iscsidSettingRegexp(key).FindStringSubmatch(content); match != nil {
+		return match[1]
+	}
+	return defaultIscsiConnTimeouts[key]
+}
+
+// setIscsidSetting replaces the value of the setting in iscsid.conf, or
+// appends the setting if it is not set
+func setIscsidSetting(content, key, value string) string {
+	line := fmt.Sprintf("%v = %v", key, value)
+	re := iscsidSettingRegexp(key)
+	if re.MatchString(content) {
+		return re.ReplaceAllLiteralString(content, line)
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + line + "\n"
+}
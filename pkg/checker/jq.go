@@ -0,0 +1,17 @@
+package checker
+
+import (
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// NewJQChecker returns the checker of jq, which is only needed by the user
+// scripts parsing JSON
+func NewJQChecker(options *Options) *BinaryChecker {
+	return NewBinaryChecker("jq", options, []string{"jq"}, map[types.PackageManager]string{
+		types.PackageManagerApt:    "jq",
+		types.PackageManagerYum:    "jq",
+		types.PackageManagerZypper: "jq",
+		types.PackageManagerApk:    "jq",
+		types.PackageManagerPacman: "jq",
+	})
+}
@@ -0,0 +1,100 @@
+package checker
+
+import (
+	"regexp"
+	"strings"
+)
+
+// kubeletUnits are the systemd units running the kubelet, which is embedded
+// in the k3s and RKE2 services
+var kubeletUnits = []string{"kubelet.service", "k3s.service", "k3s-agent.service", "rke2-server.service", "rke2-agent.service"}
+
+var delegateRegexp = regexp.MustCompile(`(?m)^\s*Delegate\s*=\s*(.*?)\s*$`)
+
+// KubeletDelegateChecker checks that the systemd unit of the kubelet sets
+// Delegate=yes, without which systemd manages the cgroups of the pods under
+// it and the resources of the instance-manager may be misaccounted
+type KubeletDelegateChecker struct {
+	host Host
+}
+
+func NewKubeletDelegateChecker(options *Options) *KubeletDelegateChecker {
+	return &KubeletDelegateChecker{
+		host: options.Host,
+	}
+}
+
+func (c *KubeletDelegateChecker) Name() string {
+	return "kubelet-delegate"
+}
+
+func (c *KubeletDelegateChecker) Check() *CheckResult {
+	systemd, err := isSystemd(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to detect systemd: %v", err)
+	}
+	if !systemd {
+		return newResult(c.Name(), StatusSkip, "host is not running systemd")
+	}
+
+	for _, unit := range kubeletUnits {
+		files, err := c.getUnitFiles(unit)
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to find the files of %v: %v", unit, err)
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		// The drop-ins come after the unit file, and the last setting wins
+		value, source := "", ""
+		for _, file := range files {
+			content, err := c.host.ReadFile(file)
+			if err != nil {
+				return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", file, err)
+			}
+			if matches := delegateRegexp.FindAllStringSubmatch(content, -1); len(matches) > 0 {
+				value, source = matches[len(matches)-1][1], file
+			}
+		}
+
+		if value == "" {
+			return newResult(c.Name(), StatusWarn, "%v does not set Delegate, set Delegate=yes to delegate the cgroups of the pods to the kubelet", unit)
+		}
+		if !isDelegateEnabled(value) {
+			return newResult(c.Name(), StatusWarn, "%v sets Delegate=%v (%v), set Delegate=yes to delegate the cgroups of the pods to the kubelet", unit, value, source)
+		}
+		return newResult(c.Name(), StatusPass, "%v sets Delegate=%v (%v)", unit, value, source)
+	}
+	return newResult(c.Name(), StatusSkip, "no kubelet unit found among %v", strings.Join(kubeletUnits, ", "))
+}
+
+// getUnitFiles returns the unit file followed by the drop-ins of the unit, or
+// none if the unit is not found
+func (c *KubeletDelegateChecker) getUnitFiles(unit string) ([]string, error) {
+	output, err := systemctl(c.host, "show", "-p", "FragmentPath", "-p", "DropInPaths", unit)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			properties[key] = strings.TrimSpace(value)
+		}
+	}
+	if properties["FragmentPath"] == "" {
+		return nil, nil
+	}
+	return append([]string{properties["FragmentPath"]}, strings.Fields(properties["DropInPaths"])...), nil
+}
+
+// isDelegateEnabled checks whether the value of Delegate enables the
+// delegation, which is a boolean or a list of the delegated controllers
+func isDelegateEnabled(value string) bool {
+	switch strings.ToLower(value) {
+	case "no", "false", "off", "0":
+		return false
+	}
+	return true
+}
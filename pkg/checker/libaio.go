@@ -0,0 +1,110 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// libaioPackages are the packages providing libaio, in the order they are
+// tried. Ubuntu 24.04 renamed libaio1 to libaio1t64.
+var libaioPackages = map[types.PackageManager][]string{
+	types.PackageManagerApt:    {"libaio1t64", "libaio1"},
+	types.PackageManagerYum:    {"libaio"},
+	types.PackageManagerZypper: {"libaio1"},
+	types.PackageManagerApk:    {"libaio"},
+	types.PackageManagerPacman: {"libaio"},
+}
+
+// LibaioChecker checks that libaio used by the v1 data engine for the
+// asynchronous I/O is installed, and installs it in install mode
+type LibaioChecker struct {
+	host           Host
+	packageManager types.PackageManager
+	installer      *installer.Installer
+}
+
+func NewLibaioChecker(options *Options) *LibaioChecker {
+	return &LibaioChecker{
+		host:           options.Host,
+		packageManager: options.PackageManager,
+		installer:      options.Installer,
+	}
+}
+
+func (c *LibaioChecker) Name() string {
+	return "libaio"
+}
+
+func (c *LibaioChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionPackageLock, PreconditionUsrWritable}
+}
+
+func (c *LibaioChecker) Check() *CheckResult {
+	packages, ok := libaioPackages[c.packageManager]
+	if !ok {
+		return newResult(c.Name(), StatusSkip, "Querying the packages with %v is not supported", c.packageManager)
+	}
+
+	for _, pkg := range packages {
+		installed, err := c.isInstalled(pkg)
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to query package %v: %v", pkg, err)
+		}
+		if installed {
+			return newResult(c.Name(), StatusPass, "package %v is installed", pkg)
+		}
+	}
+	return newResult(c.Name(), StatusWarn, "libaio used by the v1 data engine is not installed, install package %v", strings.Join(packages, " or "))
+}
+
+// isInstalled checks whether the package is installed with the package manager
+func (c *LibaioChecker) isInstalled(pkg string) (bool, error) {
+	var script string
+	switch c.packageManager {
+	case types.PackageManagerApt:
+		script = `dpkg-query -W -f='${db:Status-Abbrev}' "$1" 2>/dev/null | grep -q '^ii'`
+	case types.PackageManagerYum, types.PackageManagerZypper:
+		script = `rpm -q "$1" >/dev/null 2>&1`
+	case types.PackageManagerApk:
+		script = `apk info -e "$1" >/dev/null 2>&1`
+	case types.PackageManagerPacman:
+		script = `pacman -Q "$1" >/dev/null 2>&1`
+	default:
+		return false, fmt.Errorf("querying the packages with %v is not supported", c.packageManager)
+	}
+
+	output, err := c.host.Execute("sh", []string{"-c", script + ` && echo installed || true`, "sh", pkg}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(output) == "installed", nil
+}
+
+func (c *LibaioChecker) Remediation() *Remediation {
+	packages, ok := libaioPackages[c.packageManager]
+	if !ok {
+		return nil
+	}
+	return newInstallPackageRemediation(packages[0], packages[1:]...)
+}
+
+func (c *LibaioChecker) Install() error {
+	packages, ok := libaioPackages[c.packageManager]
+	if !ok {
+		return fmt.Errorf("no package providing libaio for %v", c.packageManager)
+	}
+
+	// The package names differ between the releases, so the first available one is installed
+	var err error
+	for _, pkg := range packages {
+		if err = installPackage(c.installer, pkg); err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,58 @@
+package checker
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+const kernelModulesDirectory = "/lib/modules"
+
+var moduleFileSuffixes = []string{".ko", ".ko.gz", ".ko.xz", ".ko.zst"}
+
+// normalizeModuleName returns the module name as the kernel reports it, in
+// which dashes are replaced by underscores
+func normalizeModuleName(module string) string {
+	return strings.ReplaceAll(module, "-", "_")
+}
+
+// isModuleLoaded checks whether the module is loaded or built into the
+// running kernel
+func isModuleLoaded(host Host, module string) (bool, error) {
+	return host.FileExists(filepath.Join("/sys/module", normalizeModuleName(module)))
+}
+
+// findModuleFile returns the path of the module file under the modules
+// directory of the kernel release, or an empty string if there is none
+func findModuleFile(host Host, kernelRelease, module string) (string, error) {
+	name := normalizeModuleName(module)
+
+	fn := func() (string, error) {
+		path := ""
+		err := filepath.WalkDir(filepath.Join(kernelModulesDirectory, kernelRelease), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			for _, suffix := range moduleFileSuffixes {
+				base, found := strings.CutSuffix(d.Name(), suffix)
+				if found && normalizeModuleName(base) == name {
+					path = p
+					return filepath.SkipAll
+				}
+			}
+			return nil
+		})
+		return path, err
+	}
+
+	return host.Probe("module-file:"+filepath.Join(kernelRelease, name), fn)
+}
+
+// persistModules writes the modules to a modules-load.d config, so they are
+// loaded on boot by systemd-modules-load.service
+func persistModules(host Host, name string, modules []string) error {
+	return host.WriteFile(filepath.Join("/etc/modules-load.d", name+".conf"), strings.Join(modules, "\n")+"\n")
+}
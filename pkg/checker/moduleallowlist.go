@@ -0,0 +1,87 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	lhutils "github.com/longhorn/go-common-libs/utils"
+)
+
+const procModulesPath = "/proc/modules"
+
+// LoadModuleAllowlist reads the approved kernel modules from the allowlist
+// file, one module per line. Lines starting with "#" are ignored.
+func LoadModuleAllowlist(path string) (map[string]bool, error) {
+	content, err := lhutils.ReadFileContent(path)
+	if err != nil {
+		return nil, err
+	}
+
+	allowlist := map[string]bool{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowlist[normalizeModuleName(line)] = true
+	}
+	return allowlist, nil
+}
+
+// ParseModuleAllowlistSeverity validates the status reported for the modules
+// not on the allowlist
+func ParseModuleAllowlistSeverity(value string) (Status, error) {
+	switch Status(value) {
+	case StatusWarn, StatusFail:
+		return Status(value), nil
+	default:
+		return "", fmt.Errorf("invalid module allowlist severity %v, must be one of warn or fail", value)
+	}
+}
+
+// ModuleAllowlistChecker checks that only the approved kernel modules are
+// loaded for the compliance of regulated environments
+type ModuleAllowlistChecker struct {
+	host      Host
+	allowlist map[string]bool
+	severity  Status
+}
+
+func NewModuleAllowlistChecker(options *Options) *ModuleAllowlistChecker {
+	return &ModuleAllowlistChecker{
+		host:      options.Host,
+		allowlist: options.ModuleAllowlist,
+		severity:  options.ModuleAllowlistSeverity,
+	}
+}
+
+func (c *ModuleAllowlistChecker) Name() string {
+	return "module-allowlist"
+}
+
+func (c *ModuleAllowlistChecker) Check() *CheckResult {
+	content, err := c.host.ReadFile(procModulesPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", procModulesPath, err)
+	}
+
+	loaded := 0
+	unexpected := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		loaded++
+		if !c.allowlist[fields[0]] {
+			unexpected = append(unexpected, fields[0])
+		}
+	}
+
+	if len(unexpected) > 0 {
+		sort.Strings(unexpected)
+		return newResult(c.Name(), c.severity, "%d of %d loaded modules are not on the allowlist: %v", len(unexpected), loaded, strings.Join(unexpected, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "All %d loaded modules are on the allowlist", loaded)
+}
@@ -0,0 +1,101 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+)
+
+const PreconditionRequiredModules = "required-modules"
+
+// ModuleFileChecker checks that the required kernel modules are available for
+// the running kernel, which is not the case when the kernel is upgraded and
+// the modules of the new kernel are not installed
+type ModuleFileChecker struct {
+	host    Host
+	modules []string
+}
+
+func NewModuleFileChecker(options *Options, modules []string) *ModuleFileChecker {
+	return &ModuleFileChecker{
+		host:    options.Host,
+		modules: modules,
+	}
+}
+
+func (c *ModuleFileChecker) Name() string {
+	return "kernel-module-files"
+}
+
+func (c *ModuleFileChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionRequiredModules, PreconditionModulesDirectory}
+}
+
+func (c *ModuleFileChecker) Check() *CheckResult {
+	kernelRelease, err := c.host.KernelRelease()
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to get kernel release: %v", err)
+	}
+
+	status := StatusPass
+	details := []string{}
+	for _, module := range c.modules {
+		path, err := findModuleFile(c.host, kernelRelease, module)
+		if err != nil {
+			status = worstStatus(status, StatusFail)
+			details = append(details, fmt.Sprintf("%v: failed to look up module file: %v", module, err))
+			continue
+		}
+
+		loaded, err := isModuleLoaded(c.host, module)
+		if err != nil {
+			status = worstStatus(status, StatusFail)
+			details = append(details, fmt.Sprintf("%v: failed to check whether it is loaded: %v", module, err))
+			continue
+		}
+		switch {
+		case loaded && path != "":
+			details = append(details, fmt.Sprintf("%v: loaded from %v", module, path))
+		case loaded:
+			details = append(details, fmt.Sprintf("%v: loaded (built-in)", module))
+		case path != "":
+			status = worstStatus(status, StatusWarn)
+			details = append(details, fmt.Sprintf("%v: present but not loaded (%v)", module, path))
+		default:
+			status = worstStatus(status, StatusFail)
+			details = append(details, fmt.Sprintf("%v: module file missing for running kernel", module))
+		}
+	}
+
+	return newResult(c.Name(), status, "kernel %v: %v", kernelRelease, strings.Join(details, "; "))
+}
+
+// Remediation loads the modules, which only helps with the modules present but
+// not loaded. The missing module files require installing the modules package
+// of the running kernel by hand.
+func (c *ModuleFileChecker) Remediation() *Remediation {
+	return newLoadModuleRemediation(c.modules)
+}
+
+// getRequiredModules returns the kernel modules installed for the package
+// manager and used by the targeted Longhorn release
+func getRequiredModules(options *Options) ([]string, error) {
+	modules, err := installer.GetModules(options.PackageManager)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := map[string]bool{}
+	for _, module := range options.Requirements.ExcludedModules {
+		excluded[module] = true
+	}
+
+	required := []string{}
+	for _, module := range modules {
+		if !excluded[module] {
+			required = append(required, module)
+		}
+	}
+	return required, nil
+}
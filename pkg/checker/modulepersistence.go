@@ -0,0 +1,119 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// modulesLoadConfigPath is read by systemd-modules-load.service on boot
+	modulesLoadConfigPath = "/etc/modules-load.d/longhorn.conf"
+	// etcModulesPath is read on boot by the init systems other than systemd,
+	// e.g. the modules service of OpenRC
+	etcModulesPath = "/etc/modules"
+)
+
+// ModulePersistenceChecker checks that the required modules are persisted to
+// be loaded on boot, since modprobe only loads them until the next reboot,
+// and persists them in install mode
+type ModulePersistenceChecker struct {
+	host    Host
+	modules []string
+}
+
+func NewModulePersistenceChecker(options *Options, modules []string) *ModulePersistenceChecker {
+	return &ModulePersistenceChecker{
+		host:    options.Host,
+		modules: modules,
+	}
+}
+
+func (c *ModulePersistenceChecker) Name() string {
+	return "module-persistence"
+}
+
+func (c *ModulePersistenceChecker) Check() *CheckResult {
+	path, err := c.getConfigPath()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to detect systemd: %v", err)
+	}
+
+	_, persisted, err := c.readConfig(path)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", path, err)
+	}
+
+	missing := []string{}
+	for _, module := range c.modules {
+		if !persisted[normalizeModuleName(module)] {
+			missing = append(missing, module)
+		}
+	}
+	if len(missing) > 0 {
+		return newResult(c.Name(), StatusWarn, "%v does not persist modules %v, which are not loaded on boot",
+			path, strings.Join(missing, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "%v persists modules %v", path, strings.Join(c.modules, ", "))
+}
+
+// getConfigPath returns the config loading the modules on boot on the host
+func (c *ModulePersistenceChecker) getConfigPath() (string, error) {
+	systemd, err := isSystemd(c.host)
+	if err != nil {
+		return "", err
+	}
+	if systemd {
+		return modulesLoadConfigPath, nil
+	}
+	return etcModulesPath, nil
+}
+
+// readConfig returns the content of the config and the modules it lists, a
+// module per line with the comments starting with # or ;, with the names
+// normalized
+func (c *ModulePersistenceChecker) readConfig(path string) (string, map[string]bool, error) {
+	modules := map[string]bool{}
+	exists, err := c.host.FileExists(path)
+	if err != nil || !exists {
+		return "", modules, err
+	}
+
+	content, err := c.host.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		modules[normalizeModuleName(fields[0])] = true
+	}
+	return content, modules, nil
+}
+
+func (c *ModulePersistenceChecker) Install() error {
+	path, err := c.getConfigPath()
+	if err != nil {
+		return err
+	}
+	if path == modulesLoadConfigPath {
+		return persistModules(c.host, "longhorn", c.modules)
+	}
+
+	// /etc/modules is shared with the other modules, so the missing ones are
+	// appended to it
+	content, persisted, err := c.readConfig(path)
+	if err != nil {
+		return err
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	for _, module := range c.modules {
+		if !persisted[normalizeModuleName(module)] {
+			content += fmt.Sprintln(module)
+		}
+	}
+	return c.host.WriteFile(path, content)
+}
@@ -0,0 +1,81 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+// ModuleChecker checks that the kernel modules are loaded or at least
+// loadable, and loads them in install mode
+type ModuleChecker struct {
+	name    string
+	host    Host
+	modules []string
+}
+
+func NewModuleChecker(name string, options *Options, modules []string) *ModuleChecker {
+	return &ModuleChecker{
+		name:    name,
+		host:    options.Host,
+		modules: modules,
+	}
+}
+
+func (c *ModuleChecker) Name() string {
+	return c.name
+}
+
+func (c *ModuleChecker) Check() *CheckResult {
+	status := StatusPass
+	states := []string{}
+	for _, module := range c.modules {
+		state, err := getModuleState(c.host, module)
+		if err != nil {
+			status = worstStatus(status, StatusWarn)
+			states = append(states, fmt.Sprintf("%v: %v", module, err))
+			continue
+		}
+		if state == moduleStateUnavailable {
+			status = worstStatus(status, StatusWarn)
+		}
+		states = append(states, fmt.Sprintf("%v: %v", module, state))
+	}
+	return newResult(c.Name(), status, "%v", strings.Join(states, ", "))
+}
+
+func (c *ModuleChecker) Install() error {
+	for _, module := range c.modules {
+		if _, err := c.host.Execute("modprobe", []string{module}, lhtypes.ExecuteDefaultTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ModuleChecker) Remediation() *Remediation {
+	return newLoadModuleRemediation(c.modules)
+}
+
+const (
+	moduleStateLoaded      = "loaded"
+	moduleStateLoadable    = "loadable"
+	moduleStateUnavailable = "unavailable"
+)
+
+// getModuleState returns whether the module is loaded, loadable or unavailable
+func getModuleState(host Host, module string) (string, error) {
+	loaded, err := isModuleLoaded(host, module)
+	if err != nil {
+		return "", err
+	}
+	if loaded {
+		return moduleStateLoaded, nil
+	}
+
+	if _, err := host.Execute("modprobe", []string{"--dry-run", module}, lhtypes.ExecuteDefaultTimeout); err != nil {
+		return moduleStateUnavailable, nil
+	}
+	return moduleStateLoadable, nil
+}
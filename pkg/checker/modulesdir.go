@@ -0,0 +1,49 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const PreconditionModulesDirectory = "kernel-modules-directory"
+
+// ModulesDirectoryChecker checks that the modules directory of the running
+// kernel exists on the host and is not empty, otherwise the module checks are
+// meaningless
+type ModulesDirectoryChecker struct {
+	host Host
+}
+
+func NewModulesDirectoryChecker(options *Options) *ModulesDirectoryChecker {
+	return &ModulesDirectoryChecker{
+		host: options.Host,
+	}
+}
+
+func (c *ModulesDirectoryChecker) Name() string {
+	return PreconditionModulesDirectory
+}
+
+func (c *ModulesDirectoryChecker) Check() *CheckResult {
+	kernelRelease, err := c.host.KernelRelease()
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to get kernel release: %v", err)
+	}
+
+	path := filepath.Join(kernelModulesDirectory, kernelRelease)
+	value, err := c.host.Probe("dir-entries:"+path, func() (string, error) {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(len(entries)), nil
+	})
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "running kernel %v has no modules directory %v on the host, e.g. its package was removed after installing another kernel: %v", kernelRelease, path, err)
+	}
+	if value == "0" {
+		return newResult(c.Name(), StatusFail, "modules directory %v of running kernel %v is empty on the host", path, kernelRelease)
+	}
+	return newResult(c.Name(), StatusPass, "found %v", path)
+}
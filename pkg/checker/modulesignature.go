@@ -0,0 +1,112 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const secureBootEFIVarPath = "/sys/firmware/efi/efivars/SecureBoot-8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+// ModuleSignatureChecker reports the signatures of the required kernel
+// modules, and warns about the unsigned ones when Secure Boot is enabled,
+// since the kernel in lockdown refuses to load them
+type ModuleSignatureChecker struct {
+	host    Host
+	modules []string
+}
+
+func NewModuleSignatureChecker(options *Options, modules []string) *ModuleSignatureChecker {
+	return &ModuleSignatureChecker{
+		host:    options.Host,
+		modules: modules,
+	}
+}
+
+func (c *ModuleSignatureChecker) Name() string {
+	return "kernel-module-signatures"
+}
+
+func (c *ModuleSignatureChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionRequiredModules}
+}
+
+func (c *ModuleSignatureChecker) Check() *CheckResult {
+	if _, err := lookPath(c.host, "modinfo"); err != nil {
+		return newResult(c.Name(), StatusWarn, "modinfo is not found, install package kmod")
+	}
+
+	secureBoot, err := c.isSecureBootEnabled()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the Secure Boot state: %v", err)
+	}
+
+	status := StatusPass
+	details := []string{}
+	for _, module := range c.modules {
+		output, err := c.host.Execute("modinfo", []string{module}, lhtypes.ExecuteDefaultTimeout)
+		if err != nil {
+			status = worstStatus(status, StatusWarn)
+			details = append(details, fmt.Sprintf("%v: not found", module))
+			continue
+		}
+
+		filename, signer := parseModinfo(output)
+		switch {
+		case filename == "(builtin)":
+			details = append(details, fmt.Sprintf("%v: built-in", module))
+		case signer != "":
+			details = append(details, fmt.Sprintf("%v: signed by %v", module, signer))
+		case secureBoot:
+			status = worstStatus(status, StatusWarn)
+			details = append(details, fmt.Sprintf("%v: unsigned, cannot be loaded with Secure Boot", module))
+		default:
+			details = append(details, fmt.Sprintf("%v: unsigned", module))
+		}
+	}
+
+	state := "disabled"
+	if secureBoot {
+		state = "enabled"
+	}
+	return newResult(c.Name(), status, "Secure Boot %v: %v", state, strings.Join(details, ", "))
+}
+
+// isSecureBootEnabled reads the SecureBoot EFI variable, whose data follows
+// the 4 bytes of attributes. The nodes without EFI do not have Secure Boot.
+func (c *ModuleSignatureChecker) isSecureBootEnabled() (bool, error) {
+	exists, err := c.host.FileExists(secureBootEFIVarPath)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	content, err := c.host.ReadFile(secureBootEFIVarPath)
+	if err != nil {
+		return false, err
+	}
+	if len(content) < 5 {
+		return false, fmt.Errorf("invalid SecureBoot EFI variable of %d bytes", len(content))
+	}
+	return content[4] == 1, nil
+}
+
+// parseModinfo returns the filename and the signer of the module in the
+// output of modinfo
+func parseModinfo(output string) (string, string) {
+	filename := ""
+	signer := ""
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "filename":
+			filename = strings.TrimSpace(value)
+		case "signer":
+			signer = strings.TrimSpace(value)
+		}
+	}
+	return filename, signer
+}
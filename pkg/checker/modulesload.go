@@ -0,0 +1,82 @@
+package checker
+
+import (
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const modulesLoadService = "systemd-modules-load.service"
+
+var modulesLoadDirectories = []string{"/etc/modules-load.d", "/run/modules-load.d", "/usr/lib/modules-load.d", "/lib/modules-load.d"}
+
+// ModulesLoadChecker checks that systemd-modules-load.service is not disabled
+// or masked when modules are persisted in modules-load.d, since they are not
+// loaded on boot otherwise, and enables the service in install mode
+type ModulesLoadChecker struct {
+	host Host
+}
+
+func NewModulesLoadChecker(options *Options) *ModulesLoadChecker {
+	return &ModulesLoadChecker{
+		host: options.Host,
+	}
+}
+
+func (c *ModulesLoadChecker) Name() string {
+	return "systemd-modules-load"
+}
+
+func (c *ModulesLoadChecker) Check() *CheckResult {
+	systemd, err := isSystemd(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to detect systemd: %v", err)
+	}
+	if !systemd {
+		return newResult(c.Name(), StatusSkip, "host is not running systemd")
+	}
+
+	state, err := systemctl(c.host, "is-enabled", modulesLoadService)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the state of %v: %v", modulesLoadService, err)
+	}
+	if state != "disabled" && !strings.HasPrefix(state, "masked") {
+		return newResult(c.Name(), StatusPass, "%v is %v", modulesLoadService, state)
+	}
+
+	files, err := findConfigFiles(c.host, modulesLoadDirectories, ".conf")
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "%v is %v, failed to list the modules-load.d files: %v", modulesLoadService, state, err)
+	}
+	if len(files) == 0 {
+		return newResult(c.Name(), StatusPass, "%v is %v, but no modules are persisted in modules-load.d", modulesLoadService, state)
+	}
+	return newResult(c.Name(), StatusWarn, "%v is %v, the modules persisted in %v are not loaded on boot",
+		modulesLoadService, state, strings.Join(files, ", "))
+}
+
+func (c *ModulesLoadChecker) Remediation() *Remediation {
+	return &Remediation{
+		Category: CategoryService,
+		Actions: []*RemediationAction{
+			{Type: ActionStartService, Parameters: map[string]string{"service": modulesLoadService, "enable": "true"}},
+		},
+	}
+}
+
+func (c *ModulesLoadChecker) Install() error {
+	if _, err := c.host.Execute("systemctl", []string{"unmask", modulesLoadService}, lhtypes.ExecuteDefaultTimeout); err != nil {
+		return err
+	}
+	state, err := systemctl(c.host, "is-enabled", modulesLoadService)
+	if err != nil {
+		return err
+	}
+	if state == "disabled" {
+		if _, err := c.host.Execute("systemctl", []string{"enable", modulesLoadService}, lhtypes.ExecuteDefaultTimeout); err != nil {
+			return err
+		}
+	}
+	_, err = c.host.Execute("systemctl", []string{"start", modulesLoadService}, lhtypes.ExecuteDefaultTimeout)
+	return err
+}
@@ -0,0 +1,64 @@
+package checker
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const procMountsPath = "/proc/mounts"
+
+type mountEntry struct {
+	Source     string
+	MountPoint string
+	FSType     string
+	Options    []string
+}
+
+func (m *mountEntry) IsReadOnly() bool {
+	for _, option := range m.Options {
+		if option == "ro" {
+			return true
+		}
+	}
+	return false
+}
+
+// getMounts returns the mounts in the mount namespace of the process
+func getMounts(host Host) ([]*mountEntry, error) {
+	content, err := host.ReadFile(procMountsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mounts := []*mountEntry{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		mounts = append(mounts, &mountEntry{
+			Source:     fields[0],
+			MountPoint: fields[1],
+			FSType:     fields[2],
+			Options:    strings.Split(fields[3], ","),
+		})
+	}
+	return mounts, nil
+}
+
+// findMount returns the mount containing the path. When mounts are stacked on
+// the same mount point, the last one wins.
+func findMount(mounts []*mountEntry, path string) *mountEntry {
+	path = filepath.Clean(path)
+
+	var found *mountEntry
+	for _, m := range mounts {
+		if m.MountPoint != "/" && path != m.MountPoint && !strings.HasPrefix(path, m.MountPoint+"/") {
+			continue
+		}
+		if found == nil || len(m.MountPoint) >= len(found.MountPoint) {
+			found = m
+		}
+	}
+	return found
+}
@@ -0,0 +1,91 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const selfMountInfoPath = "/proc/self/mountinfo"
+
+// MountAccessChecker checks that the key mounts of the container have the
+// access mode the operation expects: the checks only read the host root,
+// while the installation writes to the host root and /sys. The writable /proc
+// and /sys of the privileged containers are not reported in check mode.
+type MountAccessChecker struct {
+	host  Host
+	write bool
+}
+
+func NewMountAccessChecker(options *Options, write bool) *MountAccessChecker {
+	return &MountAccessChecker{
+		host:  options.Host,
+		write: write,
+	}
+}
+
+func (c *MountAccessChecker) Name() string {
+	return "mount-access"
+}
+
+func (c *MountAccessChecker) Preconditions() []string {
+	return nil
+}
+
+// expectedWritable returns the mount points and whether the operation
+// requires them to be writable
+func (c *MountAccessChecker) expectedWritable() map[string]bool {
+	return map[string]bool{
+		types.HostRootDirectory: c.write,
+		"/proc":                 false,
+		sysfsPath:               c.write,
+	}
+}
+
+func (c *MountAccessChecker) Check() *CheckResult {
+	content, err := c.host.ProbeSelf("self-file:"+selfMountInfoPath, func() (string, error) {
+		content, err := os.ReadFile(selfMountInfoPath)
+		return string(content), err
+	})
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", selfMountInfoPath, err)
+	}
+
+	access := map[string]string{}
+	for _, line := range strings.Split(content, "\n") {
+		// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		access[fields[4]] = "rw"
+		for _, option := range strings.Split(fields[5], ",") {
+			if option == "ro" {
+				access[fields[4]] = "ro"
+			}
+		}
+	}
+
+	expected := c.expectedWritable()
+	status := StatusPass
+	details := []string{}
+	for _, path := range []string{types.HostRootDirectory, "/proc", sysfsPath} {
+		mode, ok := access[path]
+		switch {
+		case !ok:
+			status = worstStatus(status, StatusWarn)
+			details = append(details, fmt.Sprintf("%v: not mounted", path))
+		case expected[path] && mode == "ro":
+			status = worstStatus(status, StatusFail)
+			details = append(details, fmt.Sprintf("%v: ro, mount it read-write for the installation", path))
+		case !expected[path] && mode == "rw" && path == types.HostRootDirectory:
+			status = worstStatus(status, StatusWarn)
+			details = append(details, fmt.Sprintf("%v: rw, mount it read-only since the checks do not write to it", path))
+		default:
+			details = append(details, fmt.Sprintf("%v: %v", path, mode))
+		}
+	}
+	return newResult(c.Name(), status, "%v", strings.Join(details, ", "))
+}
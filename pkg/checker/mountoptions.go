@@ -0,0 +1,136 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// mountOption is a mount option used by Longhorn and the first util-linux
+// version of mount supporting it
+type mountOption struct {
+	name       string
+	minVersion string
+}
+
+var mountOptions = []mountOption{
+	{name: "X-mount.mkdir", minVersion: "2.23"},
+}
+
+// utilLinuxPackages are the packages providing mount
+var utilLinuxPackages = map[types.PackageManager]string{
+	types.PackageManagerApt:    "mount",
+	types.PackageManagerYum:    "util-linux",
+	types.PackageManagerZypper: "util-linux",
+	types.PackageManagerApk:    "util-linux-misc",
+	types.PackageManagerPacman: "util-linux",
+}
+
+// MountOptionsChecker checks that mount on the host supports the options
+// Longhorn mounts the volumes with, which the old or BusyBox mount rejects,
+// and that XFS is available for the nouuid option mounting the clones. The
+// package providing mount is updated in install mode.
+type MountOptionsChecker struct {
+	host           Host
+	packageManager types.PackageManager
+	installer      *installer.Installer
+}
+
+func NewMountOptionsChecker(options *Options) *MountOptionsChecker {
+	return &MountOptionsChecker{
+		host:           options.Host,
+		packageManager: options.PackageManager,
+		installer:      options.Installer,
+	}
+}
+
+func (c *MountOptionsChecker) Name() string {
+	return "mount-options"
+}
+
+func (c *MountOptionsChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionPackageLock, PreconditionUsrWritable}
+}
+
+func (c *MountOptionsChecker) Check() *CheckResult {
+	output, err := c.host.Execute("mount", []string{"--version"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the mount version: %v", err)
+	}
+	// e.g. "mount from util-linux 2.37.2 (libmount 2.37.2: selinux, ...)"
+	if !strings.Contains(output, "util-linux") {
+		return newResult(c.Name(), StatusWarn, "mount is not from util-linux (%q), which may reject the options %v",
+			strings.TrimSpace(output), strings.Join(getMountOptionNames(), ", "))
+	}
+	version := parseVersion(output)
+	if version == "" {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the mount version from %q", strings.TrimSpace(output))
+	}
+
+	missing := []string{}
+	for _, option := range mountOptions {
+		if compareVersions(version, option.minVersion) < 0 {
+			missing = append(missing, fmt.Sprintf("%v (requires %v)", option.name, option.minVersion))
+		}
+	}
+
+	xfs, err := c.isXFSAvailable()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "mount from util-linux %v, failed to check XFS for nouuid: %v", version, err)
+	}
+	if !xfs {
+		missing = append(missing, "nouuid (requires XFS)")
+	}
+
+	if len(missing) > 0 {
+		return newResult(c.Name(), StatusWarn, "mount from util-linux %v does not support %v", version, strings.Join(missing, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "mount from util-linux %v supports %v, nouuid", version, strings.Join(getMountOptionNames(), ", "))
+}
+
+func (c *MountOptionsChecker) isXFSAvailable() (bool, error) {
+	filesystems, err := c.host.ReadFile("/proc/filesystems")
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(filesystems, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[len(fields)-1] == "xfs" {
+			return true, nil
+		}
+	}
+
+	state, err := getModuleState(c.host, "xfs")
+	if err != nil {
+		return false, err
+	}
+	return state != moduleStateUnavailable, nil
+}
+
+func getMountOptionNames() []string {
+	names := []string{}
+	for _, option := range mountOptions {
+		names = append(names, option.name)
+	}
+	return names
+}
+
+func (c *MountOptionsChecker) Remediation() *Remediation {
+	pkg, ok := utilLinuxPackages[c.packageManager]
+	if !ok {
+		return nil
+	}
+	return newInstallPackageRemediation(pkg)
+}
+
+func (c *MountOptionsChecker) Install() error {
+	pkg, ok := utilLinuxPackages[c.packageManager]
+	if !ok {
+		return fmt.Errorf("no package providing mount for %v", c.packageManager)
+	}
+
+	return installPackage(c.installer, pkg)
+}
@@ -0,0 +1,178 @@
+package checker
+
+import (
+	"regexp"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const (
+	multipathConfigPath = "/etc/multipath.conf"
+	multipathdService   = "multipathd.service"
+
+	// longhornSCSIVendor and longhornSCSIProduct identify the SCSI devices of
+	// the Longhorn volumes exported by the iSCSI target of the engine
+	longhornSCSIVendor  = "IET"
+	longhornSCSIProduct = "VIRTUAL-DISK"
+
+	// longhornSCSIDevnode is a device name taken by the Longhorn volumes
+	longhornSCSIDevnode = "sdb"
+
+	// multipathBlacklistMarker marks the blacklist stanza appended in install
+	// mode
+	multipathBlacklistMarker = "# Keep multipathd from claiming the Longhorn volumes"
+)
+
+// multipathBlacklistStanza keeps multipathd from claiming the Longhorn
+// devices while leaving the other SCSI devices to it
+var multipathBlacklistStanza = `
+` + multipathBlacklistMarker + `
+blacklist {
+    device {
+        vendor "` + longhornSCSIVendor + `"
+        product "` + longhornSCSIProduct + `"
+    }
+}
+`
+
+// MultipathChecker checks that multipathd does not claim the devices of the
+// Longhorn volumes, which fails mounting them, since it is either inactive or
+// blacklists the devices. The blacklist stanza is appended to the config in
+// install mode.
+type MultipathChecker struct {
+	host Host
+}
+
+func NewMultipathChecker(options *Options) *MultipathChecker {
+	return &MultipathChecker{
+		host: options.Host,
+	}
+}
+
+func (c *MultipathChecker) Name() string {
+	return "multipathd"
+}
+
+func (c *MultipathChecker) Check() *CheckResult {
+	systemd, err := isSystemd(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to detect systemd: %v", err)
+	}
+	if !systemd {
+		return newResult(c.Name(), StatusSkip, "host is not running systemd")
+	}
+
+	state, err := systemctl(c.host, "is-active", multipathdService)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the state of %v: %v", multipathdService, err)
+	}
+	if state != "active" {
+		return newResult(c.Name(), StatusPass, "%v is %v", multipathdService, state)
+	}
+
+	exists, err := c.host.FileExists(multipathConfigPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to check %v: %v", multipathConfigPath, err)
+	}
+	if !exists {
+		return newResult(c.Name(), StatusWarn, "%v is active without %v, so it may claim the Longhorn devices, blacklist them or disable %v",
+			multipathdService, multipathConfigPath, multipathdService)
+	}
+	content, err := c.host.ReadFile(multipathConfigPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", multipathConfigPath, err)
+	}
+
+	if blacklisted := isLonghornBlacklisted(content); blacklisted != "" {
+		return newResult(c.Name(), StatusPass, "%v is active, %v blacklists the Longhorn devices by %v", multipathdService, multipathConfigPath, blacklisted)
+	}
+	return newResult(c.Name(), StatusWarn, "%v is active and %v does not blacklist the Longhorn devices, blacklist vendor %q product %q or disable %v",
+		multipathdService, multipathConfigPath, longhornSCSIVendor, longhornSCSIProduct, multipathdService)
+}
+
+// isLonghornBlacklisted returns the blacklist entry of the config matching the
+// Longhorn devices, or an empty string if there is none. The entries are the
+// device name patterns, and the devices matched by vendor and product.
+func isLonghornBlacklisted(content string) string {
+	sections := []string{}
+	device := map[string]string{}
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.IndexAny(line, "#!"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case fields[len(fields)-1] == "{":
+			sections = append(sections, fields[0])
+			device = map[string]string{}
+		case fields[0] == "}":
+			if len(sections) == 0 {
+				continue
+			}
+			if strings.Join(sections, "/") == "blacklist/device" && matchesMultipathPattern(device["vendor"], longhornSCSIVendor) &&
+				(device["product"] == "" || matchesMultipathPattern(device["product"], longhornSCSIProduct)) {
+				return "vendor " + device["vendor"]
+			}
+			sections = sections[:len(sections)-1]
+		case len(fields) >= 2:
+			value := strings.Trim(strings.Join(fields[1:], " "), `"`)
+			switch strings.Join(sections, "/") {
+			case "blacklist":
+				if fields[0] == "devnode" && matchesMultipathPattern(value, longhornSCSIDevnode) {
+					return "devnode " + value
+				}
+			case "blacklist/device":
+				device[fields[0]] = value
+			}
+		}
+	}
+	return ""
+}
+
+// matchesMultipathPattern checks whether the regular expression of the config
+// matches the value
+func matchesMultipathPattern(pattern, value string) bool {
+	if pattern == "" {
+		return false
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+func (c *MultipathChecker) Install() error {
+	state, err := systemctl(c.host, "is-active", multipathdService)
+	if err != nil {
+		return err
+	}
+	if state != "active" {
+		return nil
+	}
+
+	content := ""
+	exists, err := c.host.FileExists(multipathConfigPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if content, err = c.host.ReadFile(multipathConfigPath); err != nil {
+			return err
+		}
+	}
+	if isLonghornBlacklisted(content) != "" || strings.Contains(content, multipathBlacklistMarker) {
+		return nil
+	}
+
+	if err := c.host.WriteFile(multipathConfigPath, content+multipathBlacklistStanza); err != nil {
+		return err
+	}
+	_, err = c.host.Execute("systemctl", []string{"try-reload-or-restart", multipathdService}, lhtypes.ExecuteDefaultTimeout)
+	return err
+}
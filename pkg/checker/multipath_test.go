@@ -0,0 +1,76 @@
+package checker
+
+import "testing"
+
+func TestIsLonghornBlacklisted(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "empty config",
+			content:  "",
+			expected: "",
+		},
+		{
+			name:     "blacklist appended in install mode",
+			content:  multipathBlacklistStanza,
+			expected: "vendor IET",
+		},
+		{
+			name: "vendor without product",
+			content: `blacklist {
+    device {
+        vendor "IET"
+    }
+}`,
+			expected: "vendor IET",
+		},
+		{
+			name: "vendor of another product",
+			content: `blacklist {
+    device {
+        vendor "IET"
+        product "OTHER"
+    }
+}`,
+			expected: "",
+		},
+		{
+			name: "device in blacklist_exceptions",
+			content: `blacklist_exceptions {
+    device {
+        vendor "IET"
+        product "VIRTUAL-DISK"
+    }
+}`,
+			expected: "",
+		},
+		{
+			name: "devnode pattern",
+			content: `defaults {
+    user_friendly_names yes
+}
+blacklist {
+    devnode "^sd[a-z0-9]+"
+}`,
+			expected: "devnode ^sd[a-z0-9]+",
+		},
+		{
+			name: "commented out blacklist",
+			content: `blacklist {
+#    devnode "^sd[a-z0-9]+"
+}`,
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if entry := isLonghornBlacklisted(test.content); entry != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, entry)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const (
+	nbdDevicePath    = "/dev/nbd0"
+	nbdsMaxParamPath = "/sys/module/nbd/parameters/nbds_max"
+
+	defaultNbdsMax = 16
+)
+
+// NBDChecker checks that the nbd module is loadable and /dev/nbd0 exists for
+// the NBD-based features, and loads the module with nbds_max devices in
+// install mode
+type NBDChecker struct {
+	host    Host
+	nbdsMax int
+}
+
+func NewNBDChecker(options *Options) *NBDChecker {
+	return &NBDChecker{
+		host:    options.Host,
+		nbdsMax: options.NbdsMax,
+	}
+}
+
+func (c *NBDChecker) Name() string {
+	return "nbd"
+}
+
+func (c *NBDChecker) Check() *CheckResult {
+	state, err := getModuleState(c.host, "nbd")
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the state of the nbd module: %v", err)
+	}
+	if state == moduleStateUnavailable {
+		return newResult(c.Name(), StatusWarn, "nbd module is unavailable")
+	}
+
+	exists, err := c.host.FileExists(nbdDevicePath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "nbd module is %v, failed to check %v: %v", state, nbdDevicePath, err)
+	}
+	if state == moduleStateLoadable {
+		return newResult(c.Name(), StatusWarn, "nbd module is loadable but not loaded, so %v does not exist", nbdDevicePath)
+	}
+
+	nbdsMax := "unknown"
+	if value, err := c.host.ReadFile(nbdsMaxParamPath); err == nil {
+		nbdsMax = strings.TrimSpace(value)
+	}
+	if !exists {
+		return newResult(c.Name(), StatusWarn, "nbd module is loaded with nbds_max=%v, but %v does not exist", nbdsMax, nbdDevicePath)
+	}
+	return newResult(c.Name(), StatusPass, "nbd module is loaded with nbds_max=%v, %v exists", nbdsMax, nbdDevicePath)
+}
+
+func (c *NBDChecker) Remediation() *Remediation {
+	return newLoadModuleRemediation([]string{"nbd"})
+}
+
+func (c *NBDChecker) Install() error {
+	_, err := c.host.Execute("modprobe", []string{"nbd", fmt.Sprintf("nbds_max=%d", c.nbdsMax)}, lhtypes.ExecuteDefaultTimeout)
+	return err
+}
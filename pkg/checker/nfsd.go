@@ -0,0 +1,29 @@
+package checker
+
+// NfsdChecker checks that the nfsd module is not loaded. The Longhorn nodes
+// are NFS clients of the share managers, and a loaded NFS server module
+// indicates a misconfiguration of the node.
+type NfsdChecker struct {
+	host Host
+}
+
+func NewNfsdChecker(options *Options) *NfsdChecker {
+	return &NfsdChecker{
+		host: options.Host,
+	}
+}
+
+func (c *NfsdChecker) Name() string {
+	return "nfsd"
+}
+
+func (c *NfsdChecker) Check() *CheckResult {
+	loaded, err := isModuleLoaded(c.host, "nfsd")
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to check whether the nfsd module is loaded: %v", err)
+	}
+	if loaded {
+		return newResult(c.Name(), StatusWarn, "The nfsd module is loaded, but the Longhorn nodes are only NFS clients; check whether the node is serving NFS by mistake")
+	}
+	return newResult(c.Name(), StatusPass, "The nfsd module is not loaded")
+}
@@ -0,0 +1,88 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/longhorn/longhorn-preflight/pkg/kube"
+)
+
+const PreconditionNodeStatusRBAC = "node-status-rbac"
+
+// NodeStatusRBACChecker checks that the service account can patch the node,
+// or its status for the node condition, before running the checks, so
+// writing the node status does not fail at the end of the run
+type NodeStatusRBACChecker struct {
+	host     Host
+	nodeName string
+
+	writeAnnotations bool
+	writeCondition   bool
+}
+
+func NewNodeStatusRBACChecker(options *Options) *NodeStatusRBACChecker {
+	return &NodeStatusRBACChecker{
+		host:             options.Host,
+		nodeName:         options.NodeName,
+		writeAnnotations: options.WriteNodeStatus,
+		writeCondition:   options.WriteNodeCondition,
+	}
+}
+
+func (c *NodeStatusRBACChecker) Name() string {
+	return PreconditionNodeStatusRBAC
+}
+
+func (c *NodeStatusRBACChecker) Preconditions() []string {
+	return nil
+}
+
+func (c *NodeStatusRBACChecker) Check() *CheckResult {
+	allowed := []string{}
+	for _, attributes := range c.getAttributes() {
+		value, err := c.host.ProbeSelf("can-i:"+attributes.String(), func() (string, error) {
+			client, err := kube.NewInClusterClient()
+			if err != nil {
+				return "", err
+			}
+			allowed, reason, err := client.CanI(attributes)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%t %v", allowed, reason), nil
+		})
+		if err != nil {
+			return newResult(c.Name(), StatusFail, "Failed to review the access to %v: %v", attributes, err)
+		}
+
+		ok, reason, _ := strings.Cut(value, " ")
+		if ok != "true" {
+			return newResult(c.Name(), StatusFail, "The service account is not allowed to %v, grant the verb patch on the resource nodes or nodes/status in a ClusterRole (%v)",
+				attributes, reason)
+		}
+		allowed = append(allowed, attributes.String())
+	}
+	return newResult(c.Name(), StatusPass, "The service account is allowed to %v", strings.Join(allowed, ", "))
+}
+
+// getAttributes returns the accesses required by writing the annotations and
+// the condition of the node
+func (c *NodeStatusRBACChecker) getAttributes() []*kube.ResourceAttributes {
+	attributes := []*kube.ResourceAttributes{}
+	if c.writeAnnotations {
+		attributes = append(attributes, &kube.ResourceAttributes{
+			Verb:     "patch",
+			Resource: "nodes",
+			Name:     c.nodeName,
+		})
+	}
+	if c.writeCondition {
+		attributes = append(attributes, &kube.ResourceAttributes{
+			Verb:        "patch",
+			Resource:    "nodes",
+			Subresource: "status",
+			Name:        c.nodeName,
+		})
+	}
+	return attributes
+}
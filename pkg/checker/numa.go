@@ -0,0 +1,123 @@
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const numaNodeDirectory = "/sys/devices/system/node"
+
+// NUMAChecker reports the NUMA topology of the node for the v2 data engine,
+// and checks HugePages are reserved on the NUMA node of the data device, which
+// SPDK allocates the memory of the I/O from
+type NUMAChecker struct {
+	host   Host
+	device string
+}
+
+func NewNUMAChecker(options *Options) *NUMAChecker {
+	device := ""
+	if options.DataDevice != "" {
+		device = filepath.Base(options.DataDevice)
+	}
+	return &NUMAChecker{
+		host:   options.Host,
+		device: device,
+	}
+}
+
+func (c *NUMAChecker) Name() string {
+	return "numa"
+}
+
+func (c *NUMAChecker) Check() *CheckResult {
+	path := filepath.Join(numaNodeDirectory, "online")
+	value, err := c.host.ReadFile(path)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", path, err)
+	}
+	nodes, err := parseCPUList(strings.TrimSpace(value))
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the online NUMA nodes %q: %v", value, err)
+	}
+
+	hugePages := map[int]int{}
+	topology := []string{}
+	for _, node := range nodes {
+		nodeDirectory := filepath.Join(numaNodeDirectory, fmt.Sprintf("node%d", node))
+		cpus, err := c.host.ReadFile(filepath.Join(nodeDirectory, "cpulist"))
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to read the CPUs of NUMA node %d: %v", node, err)
+		}
+		count, err := c.host.ReadFile(filepath.Join(nodeDirectory, "hugepages", "hugepages-2048kB", "nr_hugepages"))
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to read the HugePages of NUMA node %d: %v", node, err)
+		}
+		hugePages[node], _ = strconv.Atoi(strings.TrimSpace(count))
+		topology = append(topology, fmt.Sprintf("node%d: cpus %v, %d HugePages", node, strings.TrimSpace(cpus), hugePages[node]))
+	}
+	summary := fmt.Sprintf("%d NUMA node(s) (%v)", len(nodes), strings.Join(topology, "; "))
+
+	if c.device == "" || len(nodes) < 2 {
+		return newResult(c.Name(), StatusPass, "%v", summary)
+	}
+
+	node, err := c.getDeviceNode()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "%v, failed to find the NUMA node of %v: %v", summary, c.device, err)
+	}
+	if node < 0 {
+		return newResult(c.Name(), StatusPass, "%v, %v has no NUMA affinity", summary, c.device)
+	}
+	if hugePages[node] == 0 {
+		return newResult(c.Name(), StatusWarn, "%v, no HugePages are reserved on node%d local to %v, so SPDK accesses the device across NUMA nodes", summary, node, c.device)
+	}
+	return newResult(c.Name(), StatusPass, "%v, %v is local to node%d", summary, c.device, node)
+}
+
+// getDeviceNode returns the NUMA node of the PCI device backing the block
+// device, or -1 if it has no affinity. The namespaces of the NVMe devices
+// are backed by the controller, whose parent is the PCI device.
+func (c *NUMAChecker) getDeviceNode() (int, error) {
+	var lastErr error
+	for _, path := range []string{
+		filepath.Join("/sys/block", c.device, "device", "numa_node"),
+		filepath.Join("/sys/block", c.device, "device", "device", "numa_node"),
+	} {
+		value, err := c.host.ReadFile(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return strconv.Atoi(strings.TrimSpace(value))
+	}
+	return 0, lastErr
+}
+
+// parseCPUList parses the list format of the kernel, e.g. "0-3,8", used by
+// the CPU and NUMA node masks
+func parseCPUList(s string) ([]int, error) {
+	ids := []int{}
+	if s == "" {
+		return ids, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, err
+			}
+		}
+		for id := first; id <= last; id++ {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,109 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const (
+	NVMeTransportTCP  = "tcp"
+	NVMeTransportRDMA = "rdma"
+
+	infinibandClassPath = "/sys/class/infiniband"
+)
+
+var nvmeRDMAModules = []string{"nvme-rdma", "rdma_cm", "ib_core"}
+
+// NVMeRDMAChecker checks the modules of NVMe over RDMA for the v2 data engine
+// and the drivers of the RDMA devices, and loads and persists the modules in
+// install mode
+type NVMeRDMAChecker struct {
+	host Host
+}
+
+func NewNVMeRDMAChecker(options *Options) *NVMeRDMAChecker {
+	return &NVMeRDMAChecker{
+		host: options.Host,
+	}
+}
+
+func (c *NVMeRDMAChecker) Name() string {
+	return "nvme-rdma"
+}
+
+func (c *NVMeRDMAChecker) Check() *CheckResult {
+	status := StatusPass
+	states := []string{}
+	for _, module := range nvmeRDMAModules {
+		state, err := getModuleState(c.host, module)
+		if err != nil {
+			status = worstStatus(status, StatusWarn)
+			states = append(states, fmt.Sprintf("%v: %v", module, err))
+			continue
+		}
+		if state == moduleStateUnavailable {
+			status = worstStatus(status, StatusWarn)
+		}
+		states = append(states, fmt.Sprintf("%v: %v", module, state))
+	}
+
+	drivers, err := c.getHCADrivers()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "%v, failed to get the RDMA devices: %v", strings.Join(states, ", "), err)
+	}
+	if len(drivers) == 0 {
+		status = worstStatus(status, StatusWarn)
+		states = append(states, "no RDMA devices found, load the driver of the HCA, e.g. mlx5_ib")
+	} else {
+		states = append(states, "RDMA devices: "+strings.Join(drivers, ", "))
+	}
+	return newResult(c.Name(), status, "%v", strings.Join(states, ", "))
+}
+
+func (c *NVMeRDMAChecker) Remediation() *Remediation {
+	return newLoadModuleRemediation(nvmeRDMAModules)
+}
+
+func (c *NVMeRDMAChecker) Install() error {
+	for _, module := range nvmeRDMAModules {
+		if _, err := c.host.Execute("modprobe", []string{module}, lhtypes.ExecuteDefaultTimeout); err != nil {
+			return err
+		}
+	}
+	return persistModules(c.host, "longhorn-nvme-rdma", nvmeRDMAModules)
+}
+
+// getHCADrivers returns the RDMA devices with the driver modules of the HCAs
+func (c *NVMeRDMAChecker) getHCADrivers() ([]string, error) {
+	value, err := c.host.Probe("rdma-devices", func() (string, error) {
+		entries, err := os.ReadDir(infinibandClassPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", nil
+			}
+			return "", err
+		}
+
+		devices := []string{}
+		for _, entry := range entries {
+			driver, err := os.Readlink(filepath.Join(infinibandClassPath, entry.Name(), "device", "driver"))
+			if err != nil {
+				devices = append(devices, entry.Name())
+				continue
+			}
+			devices = append(devices, fmt.Sprintf("%v (%v)", entry.Name(), filepath.Base(driver)))
+		}
+		return strings.Join(devices, "\n"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return []string{}, nil
+	}
+	return strings.Split(value, "\n"), nil
+}
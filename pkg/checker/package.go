@@ -0,0 +1,17 @@
+package checker
+
+import (
+	"fmt"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+)
+
+// installPackage installs the package with the installer shared by the
+// checkers in install mode
+func installPackage(i *installer.Installer, pkg string) error {
+	if i == nil {
+		return fmt.Errorf("cannot install package %v without the installer", pkg)
+	}
+	_, err := i.InstallPackage(pkg)
+	return err
+}
@@ -0,0 +1,89 @@
+package checker
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const PreconditionPackageLock = "package-lock"
+
+var packageLockPaths = map[types.PackageManager][]string{
+	types.PackageManagerApt:    {"/var/lib/dpkg/lock-frontend", "/var/lib/dpkg/lock"},
+	types.PackageManagerYum:    {"/var/lib/rpm/.rpm.lock"},
+	types.PackageManagerZypper: {"/var/lib/rpm/.rpm.lock"},
+}
+
+// PackageLockChecker checks that no other process holds the lock of the
+// package database, which makes the installation hang or fail
+type PackageLockChecker struct {
+	host           Host
+	packageManager types.PackageManager
+}
+
+func NewPackageLockChecker(options *Options) *PackageLockChecker {
+	return &PackageLockChecker{
+		host:           options.Host,
+		packageManager: options.PackageManager,
+	}
+}
+
+func (c *PackageLockChecker) Name() string {
+	return PreconditionPackageLock
+}
+
+func (c *PackageLockChecker) Check() *CheckResult {
+	paths, ok := packageLockPaths[c.packageManager]
+	if !ok {
+		return newResult(c.Name(), StatusSkip, "package database lock of %v is not checked", c.packageManager)
+	}
+
+	for _, path := range paths {
+		holder, err := getLockHolder(c.host, path)
+		if err != nil {
+			return newResult(c.Name(), StatusFail, "Failed to check package database lock %v: %v", path, err)
+		}
+		if holder != "" {
+			return newResult(c.Name(), StatusFail,
+				"package database lock %v is held by %v, wait for it to finish before installing", path, holder)
+		}
+	}
+	return newResult(c.Name(), StatusPass, "package database is not locked")
+}
+
+// getLockHolder returns the holder of the fcntl lock on the file, or an empty
+// string if the lock is not held
+func getLockHolder(host Host, path string) (string, error) {
+	fn := func() (string, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return "", nil
+			}
+			return "", err
+		}
+		defer file.Close()
+
+		lock := unix.Flock_t{
+			Type:   unix.F_WRLCK,
+			Whence: 0,
+		}
+		if err := unix.FcntlFlock(file.Fd(), unix.F_GETLK, &lock); err != nil {
+			return "", err
+		}
+		if lock.Type == unix.F_UNLCK {
+			return "", nil
+		}
+		if lock.Pid <= 0 {
+			return "an unknown process", nil
+		}
+		return "PID " + strconv.Itoa(int(lock.Pid)), nil
+	}
+
+	return host.Probe("lock:"+path, fn)
+}
@@ -0,0 +1,42 @@
+package checker
+
+import (
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const defaultPageSize = "4096"
+
+// PageSizeChecker checks that the base page size is 4KiB for the v2 data
+// engine, since the HugePages are sized in 2MiB pages, which do not exist
+// with the 64KiB base pages on some arm64 kernels
+type PageSizeChecker struct {
+	host      Host
+	hugePages int
+}
+
+func NewPageSizeChecker(options *Options) *PageSizeChecker {
+	return &PageSizeChecker{
+		host:      options.Host,
+		hugePages: options.HugePages,
+	}
+}
+
+func (c *PageSizeChecker) Name() string {
+	return "page-size"
+}
+
+func (c *PageSizeChecker) Check() *CheckResult {
+	output, err := c.host.Execute("getconf", []string{"PAGE_SIZE"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the page size: %v", err)
+	}
+
+	pageSize := strings.TrimSpace(output)
+	if pageSize != defaultPageSize {
+		return newResult(c.Name(), StatusWarn, "Page size is %v bytes instead of %v, %d HugePages of 2MiB may not be available, adjust the HugePages of the v2 data engine",
+			pageSize, defaultPageSize, c.hugePages)
+	}
+	return newResult(c.Name(), StatusPass, "Page size is %v bytes", pageSize)
+}
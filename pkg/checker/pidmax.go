@@ -0,0 +1,58 @@
+package checker
+
+import (
+	"strconv"
+)
+
+const pidMaxSysctl = "kernel.pid_max"
+
+// PidMaxChecker checks that kernel.pid_max leaves room for the processes of
+// many volumes, and raises and persists it in install mode
+type PidMaxChecker struct {
+	host      Host
+	minPidMax int
+}
+
+func NewPidMaxChecker(options *Options) *PidMaxChecker {
+	return &PidMaxChecker{
+		host:      options.Host,
+		minPidMax: options.MinPidMax,
+	}
+}
+
+func (c *PidMaxChecker) Name() string {
+	return "pid-max"
+}
+
+func (c *PidMaxChecker) Preconditions() []string {
+	return []string{PreconditionHostNamespace, PreconditionProcSysWritable}
+}
+
+func (c *PidMaxChecker) Check() *CheckResult {
+	value, err := readSysctl(c.host, pidMaxSysctl)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", pidMaxSysctl, err)
+	}
+	pidMax, err := strconv.Atoi(value)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse %v %q: %v", pidMaxSysctl, value, err)
+	}
+
+	if pidMax < c.minPidMax {
+		return newResult(c.Name(), StatusWarn, "%v is %d, lower than %d", pidMaxSysctl, pidMax, c.minPidMax)
+	}
+	return newResult(c.Name(), StatusPass, "%v is %d", pidMaxSysctl, pidMax)
+}
+
+func (c *PidMaxChecker) Remediation() *Remediation {
+	return &Remediation{
+		Category: CategorySysctl,
+		Actions: []*RemediationAction{
+			{Type: ActionSetSysctl, Parameters: map[string]string{"key": pidMaxSysctl, "value": strconv.Itoa(c.minPidMax)}},
+		},
+	}
+}
+
+func (c *PidMaxChecker) Install() error {
+	return setSysctl(c.host, pidMaxSysctl, strconv.Itoa(c.minPidMax))
+}
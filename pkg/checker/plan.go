@@ -0,0 +1,175 @@
+package checker
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const OutputPlan = "plan"
+
+const (
+	ActionInstallPackage = "install-package"
+	ActionLoadModule     = "load-module"
+	ActionSetSysctl      = "set-sysctl"
+	ActionStartService   = "start-service"
+)
+
+const (
+	CategoryPackage = "package"
+	CategoryModule  = "kernel-module"
+	CategorySysctl  = "sysctl"
+	CategoryService = "service"
+
+	// CategoryManual is the category of the checks without remediation
+	// actions, which have to be fixed by hand
+	CategoryManual = "manual"
+)
+
+// Remediator is implemented by the checkers able to describe the remediation
+// of the checked prerequisite as actions executed by other tools
+type Remediator interface {
+	Remediation() *Remediation
+}
+
+// Remediation is the ordered list of actions fixing a check
+type Remediation struct {
+	Category string               `json:"category"`
+	Actions  []*RemediationAction `json:"actions"`
+}
+
+// RemediationAction is a single step of a remediation, e.g. installing a
+// package with the parameter "package"
+type RemediationAction struct {
+	Type       string            `json:"type"`
+	Parameters map[string]string `json:"parameters"`
+}
+
+// PlanStep is the remediation of a failing check
+type PlanStep struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	*Remediation
+}
+
+// Plan is the remediation plan of the failing checks
+type Plan struct {
+	LonghornVersion string `json:"longhornVersion"`
+
+	Steps []*PlanStep `json:"steps"`
+}
+
+// NewPlan returns the remediation plan of the checks that warned or failed
+func NewPlan(checkers []Checker, results []*CheckResult, options *Options) *Plan {
+	remediators := map[string]Remediator{}
+	for _, c := range checkers {
+		if r, ok := c.(Remediator); ok {
+			remediators[c.Name()] = r
+		}
+	}
+
+	plan := &Plan{
+		LonghornVersion: options.Requirements.Version,
+		Steps:           []*PlanStep{},
+	}
+	for _, result := range results {
+		if result.Status != StatusWarn && result.Status != StatusFail {
+			continue
+		}
+
+		remediation := &Remediation{Category: CategoryManual, Actions: []*RemediationAction{}}
+		if r, ok := remediators[result.Name]; ok {
+			if rem := r.Remediation(); rem != nil && len(rem.Actions) > 0 {
+				remediation = rem
+			}
+		}
+		plan.Steps = append(plan.Steps, &PlanStep{
+			Name:        result.Name,
+			Status:      result.Status,
+			Message:     result.Message,
+			Remediation: remediation,
+		})
+	}
+	return plan
+}
+
+// AnnotateResults sets the severity of the results, and the remediation hint
+// of the warnings and failures whose checker describes its remediation
+func AnnotateResults(checkers []Checker, results []*CheckResult) {
+	remediators := map[string]Remediator{}
+	for _, c := range checkers {
+		if r, ok := c.(Remediator); ok {
+			remediators[c.Name()] = r
+		}
+	}
+
+	for _, result := range results {
+		result.Severity = result.Status.Severity()
+		if result.Status != StatusWarn && result.Status != StatusFail {
+			continue
+		}
+		if r, ok := remediators[result.Name]; ok {
+			if remediation := r.Remediation(); remediation != nil {
+				result.Remediation = remediation.Hint()
+			}
+		}
+	}
+}
+
+// PrintPlan prints the remediation plan as JSON
+func PrintPlan(w io.Writer, plan *Plan) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(plan)
+}
+
+// newInstallPackageRemediation returns the remediation installing the package,
+// or the first available of the alternatives after it, e.g. when the package
+// is renamed in the newer releases of the distro
+func newInstallPackageRemediation(pkg string, alternatives ...string) *Remediation {
+	parameters := map[string]string{"package": pkg}
+	if len(alternatives) > 0 {
+		parameters["alternatives"] = strings.Join(alternatives, ",")
+	}
+	return &Remediation{
+		Category: CategoryPackage,
+		Actions: []*RemediationAction{
+			{Type: ActionInstallPackage, Parameters: parameters},
+		},
+	}
+}
+
+func newLoadModuleRemediation(modules []string) *Remediation {
+	remediation := &Remediation{Category: CategoryModule, Actions: []*RemediationAction{}}
+	for _, module := range modules {
+		remediation.Actions = append(remediation.Actions, &RemediationAction{
+			Type:       ActionLoadModule,
+			Parameters: map[string]string{"module": module},
+		})
+	}
+	return remediation
+}
+
+// Hint returns the remediation as a short instruction, e.g. "install package
+// nfs-common"
+func (r *Remediation) Hint() string {
+	hints := []string{}
+	for _, action := range r.Actions {
+		switch action.Type {
+		case ActionInstallPackage:
+			packages := []string{action.Parameters["package"]}
+			if alternatives := action.Parameters["alternatives"]; alternatives != "" {
+				packages = append(packages, strings.Split(alternatives, ",")...)
+			}
+			hints = append(hints, "install package "+strings.Join(packages, " or "))
+		case ActionLoadModule:
+			hints = append(hints, "load module "+action.Parameters["module"])
+		case ActionSetSysctl:
+			hints = append(hints, "set sysctl "+action.Parameters["key"]+"="+action.Parameters["value"])
+		case ActionStartService:
+			hints = append(hints, "start service "+action.Parameters["service"])
+		}
+	}
+	return strings.Join(hints, ", ")
+}
@@ -0,0 +1,111 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultPluginTimeout = 30 * time.Second
+
+// PluginChecker runs an executable of the plugin directory as an external
+// check. The contract of the plugins:
+//   - The executable is run without arguments in the container of the tool,
+//     not in the host namespaces, and is killed after the timeout.
+//   - It exits with 0 and prints a single JSON result on stdout:
+//     {"status": "pass|warn|fail|skip|unknown", "message": "..."}
+//   - The check is named after the executable file, so the exceptions and the
+//     scoring refer to the file name.
+//
+// A plugin exiting with a non-zero code, timing out or printing an invalid
+// result is reported as unknown with the error and the stderr.
+type PluginChecker struct {
+	host    Host
+	path    string
+	timeout time.Duration
+}
+
+func NewPluginChecker(options *Options, path string) *PluginChecker {
+	return &PluginChecker{
+		host:    options.Host,
+		path:    path,
+		timeout: defaultPluginTimeout,
+	}
+}
+
+func (c *PluginChecker) Name() string {
+	return filepath.Base(c.path)
+}
+
+func (c *PluginChecker) Preconditions() []string {
+	return nil
+}
+
+func (c *PluginChecker) Check() *CheckResult {
+	output, err := c.host.ProbeSelf("plugin:"+c.Name(), c.run)
+	if err != nil {
+		return newResult(c.Name(), StatusUnknown, "Failed to run plugin %v: %v", c.path, err)
+	}
+
+	result := &CheckResult{}
+	if err := json.Unmarshal([]byte(output), result); err != nil {
+		return newResult(c.Name(), StatusUnknown, "Failed to parse the result of plugin %v: %v", c.path, err)
+	}
+	if _, ok := statusSeverity[result.Status]; !ok {
+		return newResult(c.Name(), StatusUnknown, "Plugin %v returned invalid status %q", c.path, result.Status)
+	}
+	result.Name = c.Name()
+	return result
+}
+
+func (c *PluginChecker) run() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("timed out after %v", c.timeout)
+		}
+		return "", fmt.Errorf("%v: %v", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// newPluginCheckers returns the checkers of the executables in the plugin
+// directory in the order of their names
+func newPluginCheckers(options *Options) ([]Checker, error) {
+	entries, err := os.ReadDir(options.PluginDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory %v: %v", options.PluginDir, err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	checkers := []Checker{}
+	for _, name := range names {
+		checkers = append(checkers, NewPluginChecker(options, filepath.Join(options.PluginDir, name)))
+	}
+	return checkers, nil
+}
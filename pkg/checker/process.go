@@ -0,0 +1,46 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+	lhutils "github.com/longhorn/go-common-libs/utils"
+)
+
+// findProcesses returns the PIDs of the processes of the name in the host proc
+// directory
+func findProcesses(name string) ([]uint64, error) {
+	pids, err := lhutils.GetProcessPIDs(name, lhtypes.HostProcDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	// GetProcessPIDs falls back to the host namespace PID when no process is found
+	finder := lhutils.NewProcFinder(lhtypes.HostProcDirectory)
+	found := []uint64{}
+	for _, pid := range pids {
+		status, err := finder.GetProcessStatus(fmt.Sprint(pid))
+		if err != nil || status.Name != name {
+			continue
+		}
+		found = append(found, pid)
+	}
+	return found, nil
+}
+
+// isInHostMountNamespace checks whether the process shares the mount
+// namespace with the host
+func isInHostMountNamespace(pid uint64) (bool, error) {
+	hostNamespace, err := os.Readlink(filepath.Join(lhutils.GetHostNamespaceDirectory(lhtypes.HostProcDirectory), string(lhtypes.NamespaceMnt)))
+	if err != nil {
+		return false, err
+	}
+
+	namespace, err := os.Readlink(filepath.Join(lhutils.GetNamespaceDirectory(lhtypes.HostProcDirectory, fmt.Sprint(pid)), string(lhtypes.NamespaceMnt)))
+	if err != nil {
+		return false, err
+	}
+	return namespace == hostNamespace, nil
+}
@@ -0,0 +1,52 @@
+package checker
+
+import (
+	"strings"
+)
+
+const (
+	procSysPath = "/proc/sys"
+
+	PreconditionProcSysWritable = "proc-sys-writable"
+)
+
+// ProcSysWritableChecker checks that /proc/sys is writable before the
+// installation sets the sysctl parameters
+type ProcSysWritableChecker struct {
+	host Host
+}
+
+func NewProcSysWritableChecker(options *Options) *ProcSysWritableChecker {
+	return &ProcSysWritableChecker{
+		host: options.Host,
+	}
+}
+
+func (c *ProcSysWritableChecker) Name() string {
+	return PreconditionProcSysWritable
+}
+
+func (c *ProcSysWritableChecker) Check() *CheckResult {
+	mounts, err := getMounts(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to get mounts: %v", err)
+	}
+
+	m := findMount(mounts, procSysPath)
+	if m == nil {
+		return newResult(c.Name(), StatusFail, "%v is not mounted", procSysPath)
+	}
+	flags := strings.Join(m.Options, ",")
+
+	writable, err := isWritable(c.host, procSysPath)
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to check whether %v is writable: %v", procSysPath, err)
+	}
+	if m.IsReadOnly() || !writable {
+		return newResult(c.Name(), StatusFail,
+			"%v is not writable (mount %v, flags: %v), run the installation in a privileged container entering the host namespaces to set the sysctl parameters",
+			procSysPath, m.MountPoint, flags)
+	}
+
+	return newResult(c.Name(), StatusPass, "%v is writable (mount %v, flags: %v)", procSysPath, m.MountPoint, flags)
+}
@@ -0,0 +1,119 @@
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultKubeletRootDir = "/var/lib/kubelet"
+
+	mountPropagationShared  = "shared"
+	mountPropagationSlave   = "slave"
+	mountPropagationPrivate = "private"
+)
+
+// mountInfoEntry is a mount in /proc/self/mountinfo with its propagation
+type mountInfoEntry struct {
+	MountPoint  string
+	Propagation string
+}
+
+// getMountInfo returns the mounts with their propagation in the mount
+// namespace of the host
+func getMountInfo(host Host) ([]*mountInfoEntry, error) {
+	content, err := host.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+
+	// e.g. "36 35 98:0 /mnt1 /mnt2 rw,noatime shared:1 master:2 - ext3 /dev/root rw"
+	mounts := []*mountInfoEntry{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 7 {
+			continue
+		}
+		propagation := mountPropagationPrivate
+		for _, field := range fields[6:] {
+			if field == "-" {
+				break
+			}
+			if strings.HasPrefix(field, "shared:") {
+				propagation = mountPropagationShared
+				break
+			}
+			if strings.HasPrefix(field, "master:") {
+				propagation = mountPropagationSlave
+			}
+		}
+		mounts = append(mounts, &mountInfoEntry{
+			MountPoint:  fields[4],
+			Propagation: propagation,
+		})
+	}
+	return mounts, nil
+}
+
+// MountPropagationChecker checks that the mounts of the kubelet plugins and
+// pods directories are shared, otherwise the mounts made by the Longhorn CSI
+// plugin with bidirectional propagation are not visible to the pods
+type MountPropagationChecker struct {
+	host    Host
+	rootDir string
+}
+
+func NewMountPropagationChecker(options *Options) *MountPropagationChecker {
+	return &MountPropagationChecker{
+		host:    options.Host,
+		rootDir: options.KubeletRootDir,
+	}
+}
+
+func (c *MountPropagationChecker) Name() string {
+	return "mount-propagation"
+}
+
+func (c *MountPropagationChecker) Check() *CheckResult {
+	mounts, err := getMountInfo(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the mount propagation: %v", err)
+	}
+
+	status := StatusPass
+	details := []string{}
+	for _, dir := range []string{"plugins", "pods"} {
+		path := filepath.Join(c.rootDir, dir)
+
+		// The last mount stacked on the longest mount point containing the path wins
+		var found *mountInfoEntry
+		for _, m := range mounts {
+			if m.MountPoint != "/" && path != m.MountPoint && !strings.HasPrefix(path, m.MountPoint+"/") {
+				continue
+			}
+			if found == nil || len(m.MountPoint) >= len(found.MountPoint) {
+				found = m
+			}
+		}
+		if found == nil {
+			status = worstStatus(status, StatusWarn)
+			details = append(details, fmt.Sprintf("%v (mount not found)", path))
+			continue
+		}
+
+		switch found.Propagation {
+		case mountPropagationPrivate:
+			status = worstStatus(status, StatusFail)
+		case mountPropagationSlave:
+			status = worstStatus(status, StatusWarn)
+		}
+		details = append(details, fmt.Sprintf("%v is %v (mount %v)", path, found.Propagation, found.MountPoint))
+	}
+
+	if status != StatusPass {
+		return newResult(c.Name(), status, "%v, the kubelet directories must be on shared mounts for the Longhorn CSI plugin, run mount --make-rshared on the mounts",
+			strings.Join(details, ", "))
+	}
+	return newResult(c.Name(), StatusPass, "%v", strings.Join(details, ", "))
+}
@@ -0,0 +1,56 @@
+package checker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetMountInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		mountInfo string
+		expected  []*mountInfoEntry
+	}{
+		{
+			name:      "shared mount",
+			mountInfo: "36 35 98:0 / / rw,relatime shared:1 - ext4 /dev/sda1 rw\n",
+			expected:  []*mountInfoEntry{{MountPoint: "/", Propagation: mountPropagationShared}},
+		},
+		{
+			name:      "slave mount",
+			mountInfo: "37 36 0:32 / /var/lib/kubelet rw,relatime master:2 - tmpfs tmpfs rw\n",
+			expected:  []*mountInfoEntry{{MountPoint: "/var/lib/kubelet", Propagation: mountPropagationSlave}},
+		},
+		{
+			name:      "private mount without optional fields",
+			mountInfo: "38 36 0:33 / /mnt rw,relatime - tmpfs tmpfs rw\n",
+			expected:  []*mountInfoEntry{{MountPoint: "/mnt", Propagation: mountPropagationPrivate}},
+		},
+		{
+			name:      "shared and slave mount",
+			mountInfo: "39 36 0:34 / /data rw shared:3 master:1 - xfs /dev/sdb rw\n",
+			expected:  []*mountInfoEntry{{MountPoint: "/data", Propagation: mountPropagationShared}},
+		},
+		{
+			name:      "malformed lines are skipped",
+			mountInfo: "\n40 36 0:35\n41 36 0:36 / /run rw - tmpfs tmpfs rw\n",
+			expected:  []*mountInfoEntry{{MountPoint: "/run", Propagation: mountPropagationPrivate}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			host := &FactsHost{
+				facts:   map[string]Fact{fileFactKey("/proc/self/mountinfo"): {Value: test.mountInfo}},
+				missing: map[string]bool{},
+			}
+			mounts, err := getMountInfo(host)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(mounts, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, mounts)
+			}
+		})
+	}
+}
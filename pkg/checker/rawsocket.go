@@ -0,0 +1,53 @@
+package checker
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// RawSocketChecker checks that a raw AF_PACKET socket can be created in the
+// host network namespace for the networking diagnostics, which requires
+// CAP_NET_RAW and the af_packet module
+type RawSocketChecker struct {
+	host Host
+}
+
+func NewRawSocketChecker(options *Options) *RawSocketChecker {
+	return &RawSocketChecker{
+		host: options.Host,
+	}
+}
+
+func (c *RawSocketChecker) Name() string {
+	return "raw-socket"
+}
+
+func (c *RawSocketChecker) Check() *CheckResult {
+	value, err := c.host.Probe("raw-socket", func() (string, error) {
+		fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW, 0)
+		if err != nil {
+			switch {
+			case errors.Is(err, unix.EPERM), errors.Is(err, unix.EACCES):
+				return "no-capability", nil
+			case errors.Is(err, unix.EAFNOSUPPORT):
+				return "unsupported", nil
+			default:
+				return "", err
+			}
+		}
+		return "ok", unix.Close(fd)
+	})
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to create a raw socket: %v", err)
+	}
+
+	switch value {
+	case "no-capability":
+		return newResult(c.Name(), StatusWarn, "Cannot create a raw socket without CAP_NET_RAW, add the capability to the container")
+	case "unsupported":
+		return newResult(c.Name(), StatusWarn, "Cannot create a raw socket since AF_PACKET is unsupported, load the af_packet module")
+	default:
+		return newResult(c.Name(), StatusPass, "Raw AF_PACKET socket can be created")
+	}
+}
@@ -0,0 +1,216 @@
+package checker
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const (
+	// debianRebootRequiredPath is created by the Debian packages requiring a
+	// reboot, and the .pkgs file lists them
+	debianRebootRequiredPath = "/var/run/reboot-required"
+	// suseRebootNeededPath is created by zypper and transactional-update when
+	// a reboot is required, e.g. to boot the new snapshot
+	suseRebootNeededPath = "/run/reboot-needed"
+)
+
+var (
+	kernelReleaseNumberRegexp = regexp.MustCompile(`\d+`)
+	rpmBuildNumberRegexp      = regexp.MustCompile(`\.\d+$`)
+)
+
+// RebootPendingChecker consolidates the signals of a pending reboot, which
+// leaves the installed updates inactive: a newer installed kernel than the
+// running one, whose modules on disk do not match the running kernel, and the
+// distro-specific ones
+type RebootPendingChecker struct {
+	host           Host
+	packageManager types.PackageManager
+}
+
+func NewRebootPendingChecker(options *Options) *RebootPendingChecker {
+	return &RebootPendingChecker{
+		host:           options.Host,
+		packageManager: options.PackageManager,
+	}
+}
+
+func (c *RebootPendingChecker) Name() string {
+	return "reboot-pending"
+}
+
+// IsRebootRequired returns true since only rebooting clears the check
+func (c *RebootPendingChecker) IsRebootRequired() bool {
+	return true
+}
+
+func (c *RebootPendingChecker) Check() *CheckResult {
+	reasons := []string{}
+	for _, fn := range []func() (string, error){c.checkKernel, c.checkDebian, c.checkSUSE, c.checkNeedsRestarting} {
+		reason, err := fn()
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to check for a pending reboot: %v", err)
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	if len(reasons) > 0 {
+		return newResult(c.Name(), StatusWarn, "Reboot is pending: %v", strings.Join(reasons, "; "))
+	}
+	return newResult(c.Name(), StatusPass, "No reboot is pending")
+}
+
+// checkKernel compares the running kernel with the newest installed kernel
+// package
+func (c *RebootPendingChecker) checkKernel() (string, error) {
+	running, err := c.host.KernelRelease()
+	if err != nil {
+		return "", err
+	}
+
+	// The installed kernel packages are not listed with every package manager
+	installed, err := c.getInstalledKernelReleases()
+	if err != nil || len(installed) == 0 {
+		return "", nil
+	}
+
+	newest := installed[0]
+	for _, release := range installed[1:] {
+		if compareKernelReleases(release, newest) > 0 {
+			newest = release
+		}
+	}
+
+	if compareKernelReleases(newest, running) > 0 {
+		return fmt.Sprintf("running kernel %v, newest installed kernel %v", running, newest), nil
+	}
+	return "", nil
+}
+
+func (c *RebootPendingChecker) checkDebian() (string, error) {
+	exists, err := c.host.FileExists(debianRebootRequiredPath)
+	if err != nil || !exists {
+		return "", err
+	}
+
+	reason := debianRebootRequiredPath + " exists"
+	pkgsPath := debianRebootRequiredPath + ".pkgs"
+	if exists, err := c.host.FileExists(pkgsPath); err == nil && exists {
+		if content, err := c.host.ReadFile(pkgsPath); err == nil && strings.TrimSpace(content) != "" {
+			reason += fmt.Sprintf(" (packages: %v)", strings.Join(strings.Fields(content), ", "))
+		}
+	}
+	return reason, nil
+}
+
+func (c *RebootPendingChecker) checkSUSE() (string, error) {
+	exists, err := c.host.FileExists(suseRebootNeededPath)
+	if err != nil || !exists {
+		return "", err
+	}
+	return suseRebootNeededPath + " exists, e.g. a transactional update is waiting for the new snapshot to boot", nil
+}
+
+// checkNeedsRestarting runs needs-restarting of yum-utils or dnf on the RHEL
+// family, which exits with 1 when a reboot is required
+func (c *RebootPendingChecker) checkNeedsRestarting() (string, error) {
+	command := ""
+	if _, err := lookPath(c.host, "needs-restarting"); err == nil {
+		command = "needs-restarting -r"
+	} else if _, err := lookPath(c.host, "dnf"); err == nil {
+		command = "dnf needs-restarting -r"
+	} else {
+		return "", nil
+	}
+
+	output, err := c.host.Execute("sh", []string{"-c", command + " 2>&1; echo \"exit=$?\""}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return "", err
+	}
+
+	output = strings.TrimSpace(output)
+	lines := strings.Split(output, "\n")
+	switch lines[len(lines)-1] {
+	case "exit=0":
+		return "", nil
+	case "exit=1":
+		details := []string{}
+		for _, line := range lines[:len(lines)-1] {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "Reboot is required") {
+				details = append(details, line)
+			}
+		}
+		return fmt.Sprintf("%v reports a reboot is required (%v)", command, strings.Join(details, ", ")), nil
+	default:
+		// needs-restarting is unusable, e.g. the dnf plugin is not installed
+		return "", nil
+	}
+}
+
+// getInstalledKernelReleases returns the releases of the installed kernel
+// packages in the format of uname -r
+func (c *RebootPendingChecker) getInstalledKernelReleases() ([]string, error) {
+	var script string
+	switch c.packageManager {
+	case types.PackageManagerApt:
+		script = `dpkg-query -W -f='${db:Status-Abbrev} ${Package}\n' 'linux-image-[0-9]*' | awk '$1 == "ii" {print $2}' | sed 's/^linux-image-//'`
+	case types.PackageManagerYum:
+		script = `rpm -q kernel --qf '%{VERSION}-%{RELEASE}.%{ARCH}\n'`
+	case types.PackageManagerZypper:
+		script = `rpm -q kernel-default --qf '%{VERSION}-%{RELEASE}\n'`
+	default:
+		return nil, fmt.Errorf("listing kernel packages with %v is not supported", c.packageManager)
+	}
+
+	output, err := c.host.Execute("sh", []string{"-c", script}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		release := strings.TrimSpace(line)
+		if release == "" {
+			continue
+		}
+		if c.packageManager == types.PackageManagerZypper {
+			// e.g. 5.14.21-150500.55.39.1 is installed as 5.14.21-150500.55.39-default
+			release = rpmBuildNumberRegexp.ReplaceAllString(release, "") + "-default"
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+// compareKernelReleases compares the numbers in two kernel releases one by
+// one, and returns -1, 0 or 1 when a is older than, equal to or newer than b
+func compareKernelReleases(a, b string) int {
+	as := kernelReleaseNumberRegexp.FindAllString(a, -1)
+	bs := kernelReleaseNumberRegexp.FindAllString(b, -1)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	default:
+		return 0
+	}
+}
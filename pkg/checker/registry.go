@@ -0,0 +1,270 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/longhorn/longhorn-preflight/pkg/installer"
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// SelectCheckers returns the checkers of the name, or of the name qualified
+// by the target, e.g. disk-path-filesystem:/var/lib/longhorn
+func SelectCheckers(checkers []Checker, name string) ([]Checker, error) {
+	selected := []Checker{}
+	names := []string{}
+	for _, c := range checkers {
+		id := c.Name()
+		if t, ok := c.(Targeted); ok {
+			id += ":" + t.Target()
+		}
+		if c.Name() == name || id == name {
+			selected = append(selected, c)
+		}
+		names = append(names, id)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no enabled check named %v, the enabled checks are: %v", name, strings.Join(names, ", "))
+	}
+	return selected, nil
+}
+
+// NewCheckers returns the checkers enabled by the options
+func NewCheckers(options *Options) ([]Checker, error) {
+	modules, err := getRequiredModules(options)
+	if err != nil {
+		if !options.BestEffort {
+			return nil, err
+		}
+		logrus.WithError(err).Warn("Failed to get required modules, continuing in best-effort mode")
+		options.FailedPreconditions[PreconditionRequiredModules] = err.Error()
+	}
+
+	checkers := []Checker{
+		// The WSL detection comes first to warn about the misleading results up front
+		NewWSLChecker(options),
+		NewModulesDirectoryChecker(options),
+		NewModuleFileChecker(options, modules),
+		NewModuleSignatureChecker(options, modules),
+		NewSystemdStateChecker(options),
+		NewModulesLoadChecker(options),
+		NewSystemdNofileChecker(options),
+		NewRebootPendingChecker(options),
+		NewRunTmpfsChecker(options),
+		NewEtcPersistenceChecker(options),
+		NewShmChecker(options),
+		NewSwapChecker(options),
+		NewDevfsChecker(options),
+		NewEOLChecker(options),
+		NewClockChecker(options),
+		NewIscsidLocationChecker(options),
+		NewIscsiPackageChecker(options),
+		NewIscsiTimeoutChecker(options),
+		NewUmaskChecker(options),
+		NewNfsdChecker(options),
+		NewFirewallChecker(options),
+		NewPidMaxChecker(options),
+		NewInotifyChecker(options),
+		NewInstanceManagerChecker(options),
+		NewMountOptionsChecker(options),
+		// The v1 data engine is always available, so libaio is always checked
+		NewLibaioChecker(options),
+		NewStaleDMChecker(options),
+		NewMountPropagationChecker(options),
+		NewMultipathChecker(options),
+	}
+
+	if options.PersistModules {
+		persisted := modules
+		if options.EnableSPDK {
+			persisted = append(append([]string{}, modules...), installer.GetSPDKModules(options.DriverOverride)...)
+		}
+		checkers = append(checkers, NewModulePersistenceChecker(options, persisted))
+	}
+
+	if options.PackageManager == types.PackageManagerTalos {
+		checkers = append(checkers, NewTalosExtensionsChecker(options))
+	}
+
+	for _, diskPath := range options.DiskPaths {
+		checkers = append(checkers, NewDiskPathChecker(options, diskPath))
+	}
+
+	if options.EnableSPDK {
+		checkers = append(checkers,
+			NewCPUFlagsChecker(options),
+			NewModuleChecker("spdk-modules", options, installer.GetSPDKModules(options.DriverOverride)),
+			NewPageSizeChecker(options),
+			NewHugePagesChecker(options),
+			NewHugePagesFreeChecker(options),
+		)
+		if options.CheckNUMA {
+			checkers = append(checkers, NewNUMAChecker(options))
+		}
+		if options.DriverOverride == driverVFIO {
+			checkers = append(checkers,
+				NewVFIOChecker(options),
+				NewIOMMUChecker(options),
+			)
+		}
+		if options.PersistHugePages {
+			checkers = append(checkers, NewGrubHugePagesChecker(options))
+		}
+	}
+
+	if options.NVMeTransport == NVMeTransportRDMA {
+		checkers = append(checkers, NewNVMeRDMAChecker(options))
+	}
+
+	if options.EnableEncryption {
+		checkers = append(checkers, NewCryptsetupChecker(options))
+		if options.CheckHWRNG {
+			checkers = append(checkers, NewHWRNGChecker(options))
+		}
+	}
+
+	if options.DataDevice != "" {
+		checkers = append(checkers,
+			NewIOSchedulerChecker(options),
+			NewDiscardChecker(options),
+		)
+		if options.EnableSPDK {
+			checkers = append(checkers, NewBlockSizeChecker(options))
+		}
+	}
+
+	if options.CheckJQ {
+		checkers = append(checkers, NewJQChecker(options))
+	}
+
+	if options.CheckSG3 {
+		checkers = append(checkers, NewSG3Checker(options))
+	}
+
+	if options.CheckWipefs {
+		checkers = append(checkers, NewWipefsChecker(options))
+	}
+
+	if options.CheckAttrTools {
+		checkers = append(checkers, NewAttrToolsChecker(options))
+	}
+
+	if options.CheckFileCapabilities {
+		checkers = append(checkers, NewFileCapabilityChecker(options))
+	}
+
+	if options.CheckNBD {
+		checkers = append(checkers, NewNBDChecker(options))
+	}
+
+	if options.CheckHTTPTools {
+		checkers = append(checkers, NewHTTPToolsChecker(options))
+	}
+
+	if options.CheckFSMaint {
+		checkers = append(checkers, NewFSMaintChecker(options))
+	}
+
+	if options.CheckFuse {
+		checkers = append(checkers, NewFuseChecker(options))
+	}
+
+	if options.CheckIrqbalance {
+		checkers = append(checkers, NewIrqbalanceChecker(options))
+	}
+
+	if options.CheckKubeletDelegate {
+		checkers = append(checkers, NewKubeletDelegateChecker(options))
+	}
+
+	if options.CheckTuned {
+		checkers = append(checkers, NewTunedChecker(options))
+	}
+
+	if options.CheckRTC {
+		checkers = append(checkers, NewRTCChecker(options))
+	}
+
+	if options.CheckBonding {
+		checkers = append(checkers, NewBondingChecker(options))
+	}
+
+	if options.CheckRawSocket {
+		checkers = append(checkers, NewRawSocketChecker(options))
+	}
+
+	if options.CheckHostsFile {
+		checkers = append(checkers, NewHostsFileChecker(options))
+	}
+
+	if options.CheckTun {
+		checkers = append(checkers, NewModuleChecker("tun-module", options, []string{"tun"}))
+	}
+
+	if options.ModuleAllowlist != nil {
+		checkers = append(checkers, NewModuleAllowlistChecker(options))
+	}
+
+	if options.PluginDir != "" {
+		plugins, err := newPluginCheckers(options)
+		if err != nil {
+			return nil, err
+		}
+		checkers = append(checkers, plugins...)
+	}
+
+	return checkers, nil
+}
+
+// NewCheckPreconditions returns the checkers that must pass before running the checks
+func NewCheckPreconditions(options *Options) []Checker {
+	preconditions := []Checker{
+		NewDaemonSetEnvChecker(options),
+		NewMountAccessChecker(options, false),
+		NewHostNamespaceChecker(options),
+	}
+
+	if options.WriteNodeStatus || options.WriteNodeCondition {
+		preconditions = append(preconditions, NewNodeStatusRBACChecker(options))
+	}
+
+	if options.ResultsConfigMap != "" {
+		preconditions = append(preconditions, NewResultsConfigMapRBACChecker(options))
+	}
+
+	return preconditions
+}
+
+// NewStepPreconditions returns the preconditions of single steps of the
+// installation, e.g. /usr is writable for installing packages, whose failures
+// only skip the steps depending on them
+func NewStepPreconditions(options *Options) []Checker {
+	preconditions := []Checker{
+		NewUsrWritableChecker(options),
+	}
+
+	if options.RebuildInitramfs {
+		preconditions = append(preconditions, NewBootSpaceChecker(options))
+	}
+
+	return preconditions
+}
+
+// NewPreconditions returns the checkers that must pass before the installation
+func NewPreconditions(options *Options) []Checker {
+	preconditions := []Checker{
+		NewDaemonSetEnvChecker(options),
+		NewMountAccessChecker(options, true),
+		NewHostNamespaceChecker(options),
+		NewPackageLockChecker(options),
+		NewProcSysWritableChecker(options),
+	}
+
+	if options.EnableSPDK {
+		preconditions = append(preconditions, NewSysfsWritableChecker(options))
+	}
+
+	return preconditions
+}
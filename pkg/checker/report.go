@@ -0,0 +1,232 @@
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// Summary counts the check results by status
+type Summary struct {
+	Passed   int `json:"passed"`
+	Warnings int `json:"warnings"`
+	Failed   int `json:"failed"`
+	Skipped  int `json:"skipped"`
+	Unknown  int `json:"unknown"`
+}
+
+// Report is the rendered output of a check run
+type Report struct {
+	LonghornVersion string `json:"longhornVersion"`
+
+	Results []*CheckResult `json:"results"`
+	Summary *Summary       `json:"summary"`
+	Score   *Score         `json:"score,omitempty"`
+}
+
+// Result returns the overall result: PASS, WARN, UNKNOWN or FAIL. The result
+// is UNKNOWN when any check cannot be evaluated, as it may fail.
+func (s *Summary) Result() string {
+	switch {
+	case s.Failed > 0:
+		return "FAIL"
+	case s.Unknown > 0:
+		return "UNKNOWN"
+	case s.Warnings > 0:
+		return "WARN"
+	default:
+		return "PASS"
+	}
+}
+
+// String returns the counts of the results by status
+func (s *Summary) String() string {
+	return fmt.Sprintf("%d passed, %d warnings, %d failed, %d skipped, %d unknown", s.Passed, s.Warnings, s.Failed, s.Skipped, s.Unknown)
+}
+
+func Summarize(results []*CheckResult) *Summary {
+	summary := &Summary{}
+	for _, result := range results {
+		switch result.Status {
+		case StatusPass:
+			summary.Passed++
+		case StatusWarn:
+			summary.Warnings++
+		case StatusFail:
+			summary.Failed++
+		case StatusSkip:
+			summary.Skipped++
+		case StatusUnknown:
+			summary.Unknown++
+		}
+	}
+	return summary
+}
+
+// ParseMinSeverity validates the minimum severity of the rendered results
+func ParseMinSeverity(value string) (Status, error) {
+	switch Status(value) {
+	case "", StatusPass:
+		return StatusPass, nil
+	case StatusWarn, StatusFail:
+		return Status(value), nil
+	default:
+		return "", fmt.Errorf("invalid minimum severity %v, must be one of warn or fail", value)
+	}
+}
+
+// FilterResults returns the results at least as severe as minSeverity
+func FilterResults(results []*CheckResult, minSeverity Status) []*CheckResult {
+	filtered := []*CheckResult{}
+	for _, result := range results {
+		if statusSeverity[result.Status] >= statusSeverity[minSeverity] {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// PrintReport prints the report in the output format
+func PrintReport(w io.Writer, output string, report *Report) error {
+	switch output {
+	case OutputText:
+		printText(w, report)
+		return nil
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case OutputYAML:
+		printYAML(w, report)
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %v", output)
+	}
+}
+
+func printText(w io.Writer, report *Report) {
+	fmt.Fprintf(w, "Longhorn version: %s\n", report.LonghornVersion)
+	for _, result := range report.Results {
+		stability := ""
+		if result.Stability != "" {
+			stability = fmt.Sprintf(" (%s)", result.Stability)
+		}
+		fmt.Fprintf(w, "[%s] %s%s: %s\n", strings.ToUpper(string(result.Status)), result.ID(), stability, result.Message)
+		if result.Remediation != "" {
+			fmt.Fprintf(w, "  remediation: %s\n", result.Remediation)
+		}
+	}
+	fmt.Fprintf(w, "Summary: %s\n", report.Summary)
+
+	grouped, groups := 0, 0
+	for _, result := range report.Results {
+		if len(result.Details) > 0 {
+			grouped += len(result.Details)
+			groups++
+		}
+	}
+	if groups > 0 {
+		fmt.Fprintf(w, "Grouped: %d identical results of several targets into %d\n", grouped, groups)
+	}
+
+	if report.Score != nil {
+		contributors := []string{}
+		for _, c := range report.Score.Contributors {
+			contributors = append(contributors, fmt.Sprintf("%s (-%.1f)", c.Name, c.Deduction))
+		}
+		capped := ""
+		if report.Score.Capped {
+			capped = ", capped by failed mandatory checks"
+		}
+		fmt.Fprintf(w, "Score: %d (%s%s)", report.Score.Value, report.Score.Grade, capped)
+		if len(contributors) > 0 {
+			fmt.Fprintf(w, ", top deductions: %s", strings.Join(contributors, ", "))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// printYAML prints the report as YAML. The strings are double-quoted with
+// JSON escaping, which is valid YAML.
+func printYAML(w io.Writer, report *Report) {
+	fmt.Fprintf(w, "longhornVersion: %s\n", strconv.Quote(report.LonghornVersion))
+	if len(report.Results) == 0 {
+		fmt.Fprintln(w, "results: []")
+	} else {
+		fmt.Fprintln(w, "results:")
+	}
+	for _, result := range report.Results {
+		printYAMLResult(w, "", result)
+	}
+	fmt.Fprintln(w, "summary:")
+	fmt.Fprintf(w, "  passed: %d\n", report.Summary.Passed)
+	fmt.Fprintf(w, "  warnings: %d\n", report.Summary.Warnings)
+	fmt.Fprintf(w, "  failed: %d\n", report.Summary.Failed)
+	fmt.Fprintf(w, "  skipped: %d\n", report.Summary.Skipped)
+	fmt.Fprintf(w, "  unknown: %d\n", report.Summary.Unknown)
+
+	if report.Score != nil {
+		fmt.Fprintln(w, "score:")
+		fmt.Fprintf(w, "  value: %d\n", report.Score.Value)
+		fmt.Fprintf(w, "  grade: %s\n", strconv.Quote(report.Score.Grade))
+		fmt.Fprintf(w, "  capped: %t\n", report.Score.Capped)
+		if len(report.Score.Contributors) == 0 {
+			fmt.Fprintln(w, "  contributors: []")
+		} else {
+			fmt.Fprintln(w, "  contributors:")
+		}
+		for _, c := range report.Score.Contributors {
+			fmt.Fprintf(w, "  - name: %s\n", strconv.Quote(c.Name))
+			fmt.Fprintf(w, "    deduction: %g\n", c.Deduction)
+		}
+	}
+}
+
+func printYAMLResult(w io.Writer, indent string, result *CheckResult) {
+	fmt.Fprintf(w, "%s- name: %s\n", indent, strconv.Quote(result.Name))
+	fmt.Fprintf(w, "%s  status: %s\n", indent, strconv.Quote(string(result.Status)))
+	fmt.Fprintf(w, "%s  message: %s\n", indent, strconv.Quote(result.Message))
+	if result.Target != "" {
+		fmt.Fprintf(w, "%s  target: %s\n", indent, strconv.Quote(result.Target))
+	}
+	if result.Flaky {
+		fmt.Fprintf(w, "%s  flaky: true\n", indent)
+	}
+	if result.Stability != "" {
+		fmt.Fprintf(w, "%s  stability: %s\n", indent, strconv.Quote(result.Stability))
+	}
+	if result.Severity != "" {
+		fmt.Fprintf(w, "%s  severity: %s\n", indent, strconv.Quote(result.Severity))
+	}
+	if result.Remediation != "" {
+		fmt.Fprintf(w, "%s  remediation: %s\n", indent, strconv.Quote(result.Remediation))
+	}
+	if len(result.Details) > 0 {
+		fmt.Fprintf(w, "%s  details:\n", indent)
+		for _, detail := range result.Details {
+			printYAMLResult(w, indent+"  ", detail)
+		}
+	}
+}
+
+// PrintTrailer prints a stable one-line result for the log scrapers reading
+// the last line of the output, e.g. "PREFLIGHT_RESULT: FAIL (3 failures, 1 warning)"
+func PrintTrailer(w io.Writer, summary *Summary) {
+	fmt.Fprintf(w, "PREFLIGHT_RESULT: %s (%s, %s)\n", summary.Result(),
+		pluralize(summary.Failed, "failure"), pluralize(summary.Warnings, "warning"))
+}
+
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,83 @@
+package checker
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// latestLonghornVersion is the latest supported Longhorn minor release
+const latestLonghornVersion = "v1.8"
+
+// requirementsData maps the first Longhorn minor releases of the sets of
+// prerequisites to them, which hold until the next entry. Bump
+// latestLonghornVersion for every new minor release, and add an entry to
+// requirements.json only when its prerequisites change.
+//
+//go:embed requirements.json
+var requirementsData []byte
+
+// Requirements are the prerequisites of a Longhorn release
+type Requirements struct {
+	// Version is the minor release, e.g. v1.6
+	Version string `json:"-"`
+
+	// V2DataEngine is whether the release supports the v2 data engine
+	V2DataEngine bool `json:"v2DataEngine"`
+	// ExcludedModules are the kernel modules installed for the package
+	// manager but not used by the release
+	ExcludedModules []string `json:"excludedModules"`
+	// HugePages is the default number of 2MiB HugePages of the v2 data engine
+	HugePages int `json:"hugePages"`
+}
+
+// mustGetRequirements returns the prerequisites of the latest release, and
+// panics if the embedded requirements are invalid
+func mustGetRequirements() *Requirements {
+	requirements, err := GetRequirements("")
+	if err != nil {
+		panic(err)
+	}
+	return requirements
+}
+
+// GetRequirements returns the prerequisites of the Longhorn release, e.g.
+// v1.6.2 or 1.6, or of the latest release if the version is empty
+func GetRequirements(version string) (*Requirements, error) {
+	table := map[string]*Requirements{}
+	if err := json.Unmarshal(requirementsData, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse the embedded requirements: %v", err)
+	}
+
+	minor := latestLonghornVersion
+	if version != "" {
+		parts := strings.Split(parseVersion(version), ".")
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid Longhorn version %v", version)
+		}
+		minor = "v" + parts[0] + "." + parts[1]
+	}
+	if compareMinorVersions(minor, latestLonghornVersion) > 0 {
+		return nil, fmt.Errorf("unsupported Longhorn version %v, the latest supported release is %v", version, latestLonghornVersion)
+	}
+
+	// The prerequisites are the ones of the newest entry not newer than the release
+	since := ""
+	for v := range table {
+		if compareMinorVersions(v, minor) <= 0 && (since == "" || compareMinorVersions(v, since) > 0) {
+			since = v
+		}
+	}
+	if since == "" {
+		return nil, fmt.Errorf("unsupported Longhorn version %v", version)
+	}
+	requirements := table[since]
+	requirements.Version = minor
+	return requirements, nil
+}
+
+// compareMinorVersions compares two minor releases such as v1.6
+func compareMinorVersions(a, b string) int {
+	return compareVersions(strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v"))
+}
@@ -0,0 +1,65 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/longhorn/longhorn-preflight/pkg/kube"
+)
+
+const PreconditionResultsConfigMapRBAC = "results-configmap-rbac"
+
+// ResultsConfigMapRBACChecker checks that the service account can create and
+// patch the ConfigMap collecting the results of the nodes before running the
+// checks, so publishing the results does not fail at the end of the run
+type ResultsConfigMapRBACChecker struct {
+	host      Host
+	namespace string
+	name      string
+}
+
+func NewResultsConfigMapRBACChecker(options *Options) *ResultsConfigMapRBACChecker {
+	return &ResultsConfigMapRBACChecker{
+		host:      options.Host,
+		namespace: options.ResultsConfigMapNamespace,
+		name:      options.ResultsConfigMap,
+	}
+}
+
+func (c *ResultsConfigMapRBACChecker) Name() string {
+	return PreconditionResultsConfigMapRBAC
+}
+
+func (c *ResultsConfigMapRBACChecker) Preconditions() []string {
+	return nil
+}
+
+func (c *ResultsConfigMapRBACChecker) Check() *CheckResult {
+	// The verb create cannot be restricted to the resource names
+	for _, attributes := range []*kube.ResourceAttributes{
+		{Verb: "create", Namespace: c.namespace, Resource: "configmaps"},
+		{Verb: "patch", Namespace: c.namespace, Resource: "configmaps", Name: c.name},
+	} {
+		value, err := c.host.ProbeSelf("can-i:"+attributes.String(), func() (string, error) {
+			client, err := kube.NewInClusterClient()
+			if err != nil {
+				return "", err
+			}
+			allowed, reason, err := client.CanI(attributes)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%t %v", allowed, reason), nil
+		})
+		if err != nil {
+			return newResult(c.Name(), StatusFail, "Failed to review the access to %v: %v", attributes, err)
+		}
+
+		allowed, reason, _ := strings.Cut(value, " ")
+		if allowed != "true" {
+			return newResult(c.Name(), StatusFail, "The service account is not allowed to %v, grant the verbs create and patch on the resource configmaps in a Role (%v)",
+				attributes, reason)
+		}
+	}
+	return newResult(c.Name(), StatusPass, "The service account is allowed to create and patch ConfigMap %v/%v", c.namespace, c.name)
+}
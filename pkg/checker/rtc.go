@@ -0,0 +1,71 @@
+package checker
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const (
+	rtcSinceEpochPath = "/sys/class/rtc/rtc0/since_epoch"
+
+	maxRTCDifference = time.Minute
+)
+
+// RTCChecker checks that the real-time clock keeping the time across the
+// reboots is close to the system clock, otherwise the node boots with a wrong
+// clock until NTP syncs it, which breaks the storage operations early on
+type RTCChecker struct {
+	host Host
+}
+
+func NewRTCChecker(options *Options) *RTCChecker {
+	return &RTCChecker{
+		host: options.Host,
+	}
+}
+
+func (c *RTCChecker) Name() string {
+	return "rtc"
+}
+
+func (c *RTCChecker) Check() *CheckResult {
+	exists, err := c.host.FileExists(rtcSinceEpochPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to check %v: %v", rtcSinceEpochPath, err)
+	}
+	if !exists {
+		return newResult(c.Name(), StatusWarn, "No RTC is found in %v, the node boots with a wrong clock until NTP syncs it", rtcSinceEpochPath)
+	}
+
+	// Read both clocks in a single command to compare them at the same moment
+	output, err := c.host.Execute("sh", []string{"-c", "cat " + rtcSinceEpochPath + " && date +%s"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read the RTC: %v", err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) != 2 {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the RTC and system times %q", strings.TrimSpace(output))
+	}
+	rtcSeconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the RTC time %q: %v", fields[0], err)
+	}
+	systemSeconds, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the system time %q: %v", fields[1], err)
+	}
+
+	rtcTime := time.Unix(rtcSeconds, 0).UTC()
+	systemTime := time.Unix(systemSeconds, 0).UTC()
+	difference := rtcTime.Sub(systemTime)
+	if difference.Abs() > maxRTCDifference {
+		return newResult(c.Name(), StatusWarn, "RTC %v differs from the system clock %v by %v, the node boots with a wrong clock until NTP syncs it. "+
+			"If the RTC keeps the local time, set it to UTC with timedatectl set-local-rtc 0",
+			rtcTime.Format(time.RFC3339), systemTime.Format(time.RFC3339), difference)
+	}
+	return newResult(c.Name(), StatusPass, "RTC %v differs from the system clock %v by %v",
+		rtcTime.Format(time.RFC3339), systemTime.Format(time.RFC3339), difference)
+}
@@ -0,0 +1,51 @@
+package checker
+
+const (
+	runPath = "/run"
+
+	minRunSize = 64 << 20
+)
+
+// RunTmpfsChecker checks that /run, where the runtime sockets and lock files
+// live, is a tmpfs large enough
+type RunTmpfsChecker struct {
+	host Host
+}
+
+func NewRunTmpfsChecker(options *Options) *RunTmpfsChecker {
+	return &RunTmpfsChecker{
+		host: options.Host,
+	}
+}
+
+func (c *RunTmpfsChecker) Name() string {
+	return "run-tmpfs"
+}
+
+func (c *RunTmpfsChecker) Check() *CheckResult {
+	mounts, err := getMounts(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get mounts: %v", err)
+	}
+
+	m := findMount(mounts, runPath)
+	if m == nil || m.MountPoint != runPath {
+		return newResult(c.Name(), StatusWarn, "%v is not a separate mount", runPath)
+	}
+
+	diskStat, method, err := getDiskStat(c.host, runPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "%v is %v, failed to get its size: %v", runPath, m.FSType, err)
+	}
+
+	if m.FSType != "tmpfs" {
+		return newResult(c.Name(), StatusWarn, "%v is %v instead of tmpfs, %v free%v", runPath, m.FSType,
+			formatBytes(diskStat.StorageAvailable), describeDiskStatMethod(method))
+	}
+	if diskStat.StorageMaximum < minRunSize {
+		return newResult(c.Name(), StatusWarn, "%v is tmpfs of %v, smaller than %v, %v free%v", runPath,
+			formatBytes(diskStat.StorageMaximum), formatBytes(minRunSize), formatBytes(diskStat.StorageAvailable), describeDiskStatMethod(method))
+	}
+	return newResult(c.Name(), StatusPass, "%v is tmpfs of %v, %v free%v", runPath,
+		formatBytes(diskStat.StorageMaximum), formatBytes(diskStat.StorageAvailable), describeDiskStatMethod(method))
+}
@@ -0,0 +1,113 @@
+package checker
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	maxScore = 100
+
+	// mandatoryFailureScoreCap is the highest score when a mandatory check fails
+	mandatoryFailureScoreCap = 59
+
+	maxScoreContributors = 3
+)
+
+// Score is the grade of the node computed from the weighted check results
+type Score struct {
+	Value        int                  `json:"value"`
+	Grade        string               `json:"grade"`
+	Capped       bool                 `json:"capped"`
+	Contributors []*ScoreContribution `json:"contributors"`
+}
+
+// ScoreContribution is the deduction of a check from the score
+type ScoreContribution struct {
+	Name      string  `json:"name"`
+	Deduction float64 `json:"deduction"`
+}
+
+// ComputeScore computes the score of the node. A failed check deducts its
+// weight, and a warning or an unknown result deducts half of it. The skipped
+// checks are not counted.
+func ComputeScore(results []*CheckResult, config *ScoringConfig) *Score {
+	mandatory := map[string]bool{}
+	for _, name := range config.Mandatory {
+		mandatory[name] = true
+	}
+
+	total := 0.0
+	deductions := []*ScoreContribution{}
+	capped := false
+	for _, result := range results {
+		if result.Status == StatusSkip {
+			continue
+		}
+
+		weight, ok := config.Weights[result.Name]
+		if !ok {
+			weight = 1
+		}
+		total += weight
+
+		deduction := 0.0
+		switch result.Status {
+		case StatusFail:
+			deduction = weight
+			if mandatory[result.Name] {
+				capped = true
+			}
+		case StatusWarn, StatusUnknown:
+			deduction = weight / 2
+		}
+		if deduction > 0 {
+			deductions = append(deductions, &ScoreContribution{Name: result.Name, Deduction: deduction})
+		}
+	}
+
+	value := maxScore
+	if total > 0 {
+		deducted := 0.0
+		for _, d := range deductions {
+			deducted += d.Deduction
+		}
+		value = int(math.Round(maxScore * (1 - deducted/total)))
+	}
+	if capped && value > mandatoryFailureScoreCap {
+		value = mandatoryFailureScoreCap
+	}
+
+	sort.SliceStable(deductions, func(i, j int) bool {
+		return deductions[i].Deduction > deductions[j].Deduction
+	})
+	if len(deductions) > maxScoreContributors {
+		deductions = deductions[:maxScoreContributors]
+	}
+	// Express the deductions in points of the score
+	for _, d := range deductions {
+		d.Deduction = math.Round(maxScore*d.Deduction/total*10) / 10
+	}
+
+	return &Score{
+		Value:        value,
+		Grade:        getGrade(value),
+		Capped:       capped,
+		Contributors: deductions,
+	}
+}
+
+func getGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
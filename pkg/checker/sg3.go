@@ -0,0 +1,17 @@
+package checker
+
+import (
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// NewSG3Checker returns the checker of the sg3_utils tools used to manage
+// SCSI devices, e.g. by multipath
+func NewSG3Checker(options *Options) *BinaryChecker {
+	return NewBinaryChecker("sg3-utils", options, []string{"sg_inq", "sg_persist"}, map[types.PackageManager]string{
+		types.PackageManagerApt:    "sg3-utils",
+		types.PackageManagerYum:    "sg3_utils",
+		types.PackageManagerZypper: "sg3_utils",
+		types.PackageManagerApk:    "sg3_utils",
+		types.PackageManagerPacman: "sg3_utils",
+	})
+}
@@ -0,0 +1,49 @@
+package checker
+
+const (
+	shmPath = "/dev/shm"
+
+	defaultMinShmSize = 64 << 20
+	// spdkMinShmSize is the minimum size for the v2 data engine, whose SPDK
+	// target keeps its trace and IPC files in shared memory
+	spdkMinShmSize = 256 << 20
+)
+
+// ShmChecker checks that /dev/shm is large enough for the shared memory used
+// by the Longhorn and SPDK components
+type ShmChecker struct {
+	host    Host
+	minSize int64
+}
+
+func NewShmChecker(options *Options) *ShmChecker {
+	minSize := int64(options.MinShmSize) << 20
+	if minSize == 0 {
+		minSize = defaultMinShmSize
+		if options.EnableSPDK {
+			minSize = spdkMinShmSize
+		}
+	}
+	return &ShmChecker{
+		host:    options.Host,
+		minSize: minSize,
+	}
+}
+
+func (c *ShmChecker) Name() string {
+	return "dev-shm"
+}
+
+func (c *ShmChecker) Check() *CheckResult {
+	diskStat, method, err := getDiskStat(c.host, shmPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the size of %v: %v", shmPath, err)
+	}
+
+	if diskStat.StorageMaximum < c.minSize {
+		return newResult(c.Name(), StatusWarn, "%v is %v, smaller than %v, %v free%v", shmPath,
+			formatBytes(diskStat.StorageMaximum), formatBytes(c.minSize), formatBytes(diskStat.StorageAvailable), describeDiskStatMethod(method))
+	}
+	return newResult(c.Name(), StatusPass, "%v is %v, %v free%v", shmPath,
+		formatBytes(diskStat.StorageMaximum), formatBytes(diskStat.StorageAvailable), describeDiskStatMethod(method))
+}
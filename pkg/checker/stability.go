@@ -0,0 +1,65 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RunRepeatedly runs the checkers the given number of times, and returns the
+// most severe result of each checker noting whether its status was stable
+// across the runs. The checkers whose status varied are marked as flaky.
+func RunRepeatedly(checkers []Checker, options *Options, runs int) []*CheckResult {
+	if runs <= 1 {
+		return Run(checkers, options)
+	}
+
+	// Every run starts from the failures recorded before the first one, so
+	// that a failure in an earlier run does not skip the dependent checks
+	failedPreconditions := copyFailures(options.FailedPreconditions)
+	failedChecks := copyFailures(options.FailedChecks)
+	all := make([][]*CheckResult, runs)
+	for i := range all {
+		options.FailedPreconditions = copyFailures(failedPreconditions)
+		options.FailedChecks = copyFailures(failedChecks)
+		all[i] = Run(checkers, options)
+	}
+	options.FailedPreconditions = failedPreconditions
+	options.FailedChecks = failedChecks
+
+	results := []*CheckResult{}
+	for j := range checkers {
+		worst := all[0][j]
+		statuses := []string{}
+		stable := true
+		for i := range all {
+			result := all[i][j]
+			statuses = append(statuses, string(result.Status))
+			if result.Status != all[0][j].Status {
+				stable = false
+			}
+			if statusSeverity[result.Status] > statusSeverity[worst.Status] {
+				worst = result
+			}
+		}
+
+		if stable {
+			worst.Stability = fmt.Sprintf("stable over %d runs", runs)
+		} else {
+			worst.Flaky = true
+			worst.Stability = fmt.Sprintf("flaky over %d runs: %v", runs, strings.Join(statuses, ", "))
+		}
+		if worst.Status == StatusFail {
+			options.FailedChecks[worst.Name] = worst.Message
+		}
+		results = append(results, worst)
+	}
+	return results
+}
+
+func copyFailures(failures map[string]string) map[string]string {
+	copied := make(map[string]string, len(failures))
+	for name, reason := range failures {
+		copied[name] = reason
+	}
+	return copied
+}
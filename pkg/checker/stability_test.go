@@ -0,0 +1,100 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// sequenceChecker returns the statuses in turn on the consecutive runs
+type sequenceChecker struct {
+	name          string
+	statuses      []Status
+	preconditions []string
+	runs          int
+}
+
+func (c *sequenceChecker) Name() string {
+	return c.name
+}
+
+func (c *sequenceChecker) Preconditions() []string {
+	return c.preconditions
+}
+
+func (c *sequenceChecker) Check() *CheckResult {
+	status := c.statuses[c.runs%len(c.statuses)]
+	c.runs++
+	return newResult(c.name, status, "%v", status)
+}
+
+func TestRunRepeatedly(t *testing.T) {
+	type expected struct {
+		status    Status
+		flaky     bool
+		stability string
+	}
+	tests := []struct {
+		name     string
+		checkers []*sequenceChecker
+		runs     int
+		expected []expected
+	}{
+		{
+			name:     "stable check",
+			checkers: []*sequenceChecker{{name: "a", statuses: []Status{StatusPass}}},
+			runs:     3,
+			expected: []expected{{StatusPass, false, "stable over 3 runs"}},
+		},
+		{
+			name:     "flaky check reports the worst status",
+			checkers: []*sequenceChecker{{name: "a", statuses: []Status{StatusPass, StatusFail, StatusWarn}}},
+			runs:     3,
+			expected: []expected{{StatusFail, true, "flaky over 3 runs: pass, fail, warn"}},
+		},
+		{
+			name: "failure of an earlier run does not skip the dependent check",
+			checkers: []*sequenceChecker{
+				{name: "a", statuses: []Status{StatusFail, StatusPass, StatusPass}},
+				{name: "b", statuses: []Status{StatusPass}, preconditions: []string{"a"}},
+			},
+			runs: 3,
+			expected: []expected{
+				{StatusFail, true, "flaky over 3 runs: fail, pass, pass"},
+				{StatusUnknown, true, "flaky over 3 runs: unknown, pass, pass"},
+			},
+		},
+		{
+			name:     "single run",
+			checkers: []*sequenceChecker{{name: "a", statuses: []Status{StatusWarn}}},
+			runs:     1,
+			expected: []expected{{StatusWarn, false, ""}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkers := []Checker{}
+			for _, c := range test.checkers {
+				checkers = append(checkers, c)
+			}
+			options := NewOptions(types.PackageManagerApt)
+
+			results := RunRepeatedly(checkers, options, test.runs)
+			if len(results) != len(test.expected) {
+				t.Fatalf("expected %d results, got %d", len(test.expected), len(results))
+			}
+			for i, result := range results {
+				e := test.expected[i]
+				if result.Status != e.status || result.Flaky != e.flaky || result.Stability != e.stability {
+					t.Errorf("expected %v to be %v (flaky %v, %q), got %v (flaky %v, %q)",
+						result.Name, e.status, e.flaky, e.stability, result.Status, result.Flaky, result.Stability)
+				}
+				_, failed := options.FailedChecks[result.Name]
+				if failed != (e.status == StatusFail) {
+					t.Errorf("expected %v recorded as failed to be %v", result.Name, e.status == StatusFail)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+// longhornDevDirectory is where Longhorn creates the block devices of the
+// attached volumes
+const longhornDevDirectory = "/dev/longhorn"
+
+// longhornVolumeNameRegexp matches the names of the volumes provisioned by the
+// CSI driver, which the dm-crypt devices of the encrypted volumes are named after
+var longhornVolumeNameRegexp = regexp.MustCompile(`^pvc-[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+type dmDevice struct {
+	name      string
+	openCount string
+}
+
+// StaleDMChecker checks for the device-mapper devices of the Longhorn volumes
+// whose Longhorn block device is gone, which are left behind by an unclean
+// shutdown and block attaching the volumes again. They are removed in install
+// mode with --cleanup-stale-dm unless they are still open.
+type StaleDMChecker struct {
+	host    Host
+	cleanup bool
+}
+
+func NewStaleDMChecker(options *Options) *StaleDMChecker {
+	return &StaleDMChecker{
+		host:    options.Host,
+		cleanup: options.CleanupStaleDM,
+	}
+}
+
+func (c *StaleDMChecker) Name() string {
+	return "stale-dm-devices"
+}
+
+func (c *StaleDMChecker) Check() *CheckResult {
+	if _, err := lookPath(c.host, "dmsetup"); err != nil {
+		return newResult(c.Name(), StatusSkip, "dmsetup is not installed")
+	}
+
+	stale, err := c.getStaleDevices()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to list the device-mapper devices: %v", err)
+	}
+	if len(stale) == 0 {
+		return newResult(c.Name(), StatusPass, "No stale device-mapper device of the Longhorn volumes")
+	}
+
+	devices := []string{}
+	for _, device := range stale {
+		devices = append(devices, fmt.Sprintf("%v (open count %v)", device.name, device.openCount))
+	}
+	return newResult(c.Name(), StatusWarn, "device-mapper devices of the Longhorn volumes without the Longhorn block devices: %v, "+
+		"remove them with --cleanup-stale-dm in install mode or with dmsetup remove", strings.Join(devices, ", "))
+}
+
+// getStaleDevices returns the device-mapper devices named after the Longhorn
+// volumes, whose Longhorn block device does not exist
+func (c *StaleDMChecker) getStaleDevices() ([]*dmDevice, error) {
+	output, err := c.host.Execute("dmsetup", []string{"info", "-c", "--noheadings", "--separator", " ", "-o", "name,open"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := []*dmDevice{}
+	for _, line := range strings.Split(output, "\n") {
+		// "No devices found" when there is none
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !longhornVolumeNameRegexp.MatchString(fields[0]) {
+			continue
+		}
+		exists, err := c.host.FileExists(filepath.Join(longhornDevDirectory, fields[0]))
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			stale = append(stale, &dmDevice{name: fields[0], openCount: fields[1]})
+		}
+	}
+	return stale, nil
+}
+
+func (c *StaleDMChecker) Install() error {
+	if !c.cleanup {
+		return fmt.Errorf("removing the stale device-mapper devices is %w", errInstallDisabled)
+	}
+
+	stale, err := c.getStaleDevices()
+	if err != nil {
+		return err
+	}
+	for _, device := range stale {
+		// The open devices are still used, e.g. mounted, and removing them
+		// loses the data not flushed yet
+		if device.openCount != "0" {
+			logrus.Warnf("Skipped removing the stale device-mapper device %v since it is open %v time(s)", device.name, device.openCount)
+			continue
+		}
+		logrus.Infof("Removing the stale device-mapper device %v", device.name)
+		if _, err := c.host.Execute("dmsetup", []string{"remove", device.name}, lhtypes.ExecuteDefaultTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const procSwapsPath = "/proc/swaps"
+
+// SwapChecker checks whether swap is enabled on the node. Swapping out the
+// Longhorn processes stalls the volume I/O, but swap backed by zram stays in
+// compressed memory and is reported separately from the disk swap.
+type SwapChecker struct {
+	host Host
+}
+
+func NewSwapChecker(options *Options) *SwapChecker {
+	return &SwapChecker{
+		host: options.Host,
+	}
+}
+
+func (c *SwapChecker) Name() string {
+	return "swap"
+}
+
+func (c *SwapChecker) Check() *CheckResult {
+	content, err := c.host.ReadFile(procSwapsPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read %v: %v", procSwapsPath, err)
+	}
+
+	zram := []string{}
+	disk := []string{}
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	// Skip the header
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		device := fields[0]
+
+		isZram, err := c.isZram(device)
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to check whether swap %v is backed by zram: %v", device, err)
+		}
+		if isZram {
+			zram = append(zram, fmt.Sprintf("%v (%v KiB)", device, fields[2]))
+		} else {
+			disk = append(disk, fmt.Sprintf("%v (%v, %v KiB)", device, fields[1], fields[2]))
+		}
+	}
+
+	switch {
+	case len(disk) > 0:
+		return newResult(c.Name(), StatusWarn, "Disk swap is enabled, which stalls the volume I/O when the Longhorn processes are swapped out: %v", strings.Join(append(disk, zram...), ", "))
+	case len(zram) > 0:
+		return newResult(c.Name(), StatusPass, "Swap is zram-backed: %v", strings.Join(zram, ", "))
+	default:
+		return newResult(c.Name(), StatusPass, "Swap is disabled")
+	}
+}
+
+// isZram checks whether the swap device is a zram block device
+func (c *SwapChecker) isZram(device string) (bool, error) {
+	name := filepath.Base(device)
+	if !strings.HasPrefix(name, "zram") {
+		return false, nil
+	}
+	return c.host.FileExists(filepath.Join("/sys/block", name))
+}
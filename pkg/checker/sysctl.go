@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// sysctlConfigPath is where the installation persists the sysctl parameters
+const sysctlConfigPath = "/etc/sysctl.d/60-longhorn-preflight.conf"
+
+// getSysctlPath returns the path of the sysctl parameter under /proc/sys,
+// e.g. /proc/sys/kernel/pid_max for kernel.pid_max
+func getSysctlPath(key string) string {
+	return filepath.Join(procSysPath, strings.ReplaceAll(key, ".", "/"))
+}
+
+// readSysctl returns the value of the sysctl parameter on the host
+func readSysctl(host Host, key string) (string, error) {
+	value, err := host.ReadFile(getSysctlPath(key))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// setSysctl sets the sysctl parameter on the host right away, and persists it
+// in the sysctl config of the installation, replacing the existing value
+func setSysctl(host Host, key, value string) error {
+	if err := host.WriteFile(getSysctlPath(key), value); err != nil {
+		return err
+	}
+
+	exists, err := host.FileExists(sysctlConfigPath)
+	if err != nil {
+		return err
+	}
+	content := ""
+	if exists {
+		if content, err = host.ReadFile(sysctlConfigPath); err != nil {
+			return err
+		}
+	}
+
+	line := fmt.Sprintf("%v = %v", key, value)
+	keyRegexp := regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(key) + `\s*=.*$`)
+	if keyRegexp.MatchString(content) {
+		content = keyRegexp.ReplaceAllLiteralString(content, line)
+	} else {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += line + "\n"
+	}
+	return host.WriteFile(sysctlConfigPath, content)
+}
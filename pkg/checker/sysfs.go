@@ -0,0 +1,70 @@
+package checker
+
+import (
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	sysfsPath = "/sys"
+
+	PreconditionSysfsWritable = "sysfs-writable"
+)
+
+// SysfsWritableChecker checks that /sys is writable before the installation
+// writes to it, e.g. to reserve HugePages
+type SysfsWritableChecker struct {
+	host Host
+}
+
+func NewSysfsWritableChecker(options *Options) *SysfsWritableChecker {
+	return &SysfsWritableChecker{
+		host: options.Host,
+	}
+}
+
+func (c *SysfsWritableChecker) Name() string {
+	return PreconditionSysfsWritable
+}
+
+func (c *SysfsWritableChecker) Check() *CheckResult {
+	mounts, err := getMounts(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to get mounts: %v", err)
+	}
+
+	m := findMount(mounts, sysfsPath)
+	if m == nil {
+		return newResult(c.Name(), StatusFail, "%v is not mounted", sysfsPath)
+	}
+	flags := strings.Join(m.Options, ",")
+
+	writable, err := isWritable(c.host, sysfsPath)
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to check whether %v is writable: %v", sysfsPath, err)
+	}
+	if m.IsReadOnly() || !writable {
+		return newResult(c.Name(), StatusFail,
+			"%v is not writable (mount flags: %v), run the installation in a privileged container with the host /sys mounted read-write",
+			sysfsPath, flags)
+	}
+
+	return newResult(c.Name(), StatusPass, "%v is writable (mount flags: %v)", sysfsPath, flags)
+}
+
+// isWritable checks whether the path is writable on the host
+func isWritable(host Host, path string) (bool, error) {
+	fn := func() (string, error) {
+		if err := unix.Access(path, unix.W_OK); err != nil {
+			return "false", nil
+		}
+		return "true", nil
+	}
+
+	value, err := host.Probe("writable:"+path, fn)
+	if err != nil {
+		return false, err
+	}
+	return value == "true", nil
+}
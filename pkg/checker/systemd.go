@@ -0,0 +1,88 @@
+package checker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+const systemdRuntimeDirectory = "/run/systemd/system"
+
+// isSystemd checks whether the host is booted with systemd
+func isSystemd(host Host) (bool, error) {
+	return host.FileExists(systemdRuntimeDirectory)
+}
+
+// systemctlStateExitCodes are the non-zero exit codes the query commands exit
+// with to report the states they print, rather than failing
+var systemctlStateExitCodes = map[string][]int{
+	// 3 for the units not active, 4 for the units not found
+	"is-active": {3, 4},
+	// 1 for the units not enabled, e.g. disabled, masked or not found
+	"is-enabled": {1},
+	// 1 for the states other than running, e.g. degraded
+	"is-system-running": {1},
+}
+
+// exitCoder is the error of a command exiting with a non-zero code
+type exitCoder interface {
+	ExitCode() int
+}
+
+// systemctl runs systemctl on the host and returns the trimmed output. The
+// query commands exiting with the codes reporting the states return the
+// states, other non-zero exit codes are errors.
+func systemctl(host Host, args ...string) (string, error) {
+	// The executor only returns the output of a failed command without the
+	// timeout, and systemctl bounds the D-Bus calls with its own timeout
+	output, err := host.Execute("systemctl", args, lhtypes.ExecuteNoTimeout)
+	if err != nil {
+		var exitErr exitCoder
+		if !errors.As(err, &exitErr) || !isSystemctlStateExitCode(args[0], exitErr.ExitCode()) {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(output), nil
+}
+
+func isSystemctlStateExitCode(command string, exitCode int) bool {
+	for _, code := range systemctlStateExitCodes[command] {
+		if code == exitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// findConfigFiles returns the files with the suffix in the config directories
+// on the host, in the order of the directories and then the file names
+func findConfigFiles(host Host, dirs []string, suffix string) ([]string, error) {
+	value, err := host.Probe("config-files:"+strings.Join(dirs, ",")+":"+suffix, func() (string, error) {
+		files := []string{}
+		for _, dir := range dirs {
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return "", err
+			}
+			for _, entry := range entries {
+				if strings.HasSuffix(entry.Name(), suffix) {
+					files = append(files, filepath.Join(dir, entry.Name()))
+				}
+			}
+		}
+		return strings.Join(files, "\n"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return []string{}, nil
+	}
+	return strings.Split(value, "\n"), nil
+}
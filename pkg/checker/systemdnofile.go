@@ -0,0 +1,147 @@
+package checker
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	systemdSystemConfPath = "/etc/systemd/system.conf"
+	systemdNofileDropIn   = "/etc/systemd/system.conf.d/60-longhorn-preflight.conf"
+
+	// systemdDefaultNofile is the DefaultLimitNOFILE of systemd when unset
+	systemdDefaultNofile = "1024:524288"
+
+	minSystemdNofile = 65536
+	// recommendedSystemdNofile is the DefaultLimitNOFILE set in install mode
+	recommendedSystemdNofile = "1048576"
+)
+
+var (
+	systemdSystemConfDirectories = []string{"/usr/lib/systemd/system.conf.d", "/run/systemd/system.conf.d", "/etc/systemd/system.conf.d"}
+
+	defaultLimitNofileRegexp = regexp.MustCompile(`(?m)^\s*DefaultLimitNOFILE\s*=\s*(\S+)\s*$`)
+)
+
+// SystemdNofileChecker checks the DefaultLimitNOFILE of systemd, which limits
+// the open files of the services not setting LimitNOFILE, regardless of the
+// limit of this process, and writes a systemd drop-in in install mode
+type SystemdNofileChecker struct {
+	host Host
+}
+
+func NewSystemdNofileChecker(options *Options) *SystemdNofileChecker {
+	return &SystemdNofileChecker{
+		host: options.Host,
+	}
+}
+
+func (c *SystemdNofileChecker) Name() string {
+	return "systemd-nofile"
+}
+
+func (c *SystemdNofileChecker) Check() *CheckResult {
+	systemd, err := isSystemd(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to detect systemd: %v", err)
+	}
+	if !systemd {
+		return newResult(c.Name(), StatusSkip, "host is not running systemd")
+	}
+
+	value, source, err := c.getDefaultLimitNofile()
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to read the systemd config: %v", err)
+	}
+
+	// The value is soft:hard, or a single value for both
+	soft, hard, found := strings.Cut(value, ":")
+	if !found {
+		hard = soft
+	}
+	for _, limit := range []string{soft, hard} {
+		if limit == "infinity" {
+			continue
+		}
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return newResult(c.Name(), StatusWarn, "Failed to parse DefaultLimitNOFILE=%v in %v: %v", value, source, err)
+		}
+		if n < minSystemdNofile {
+			return newResult(c.Name(), StatusWarn, "DefaultLimitNOFILE=%v (%v) is lower than %d", value, source, minSystemdNofile)
+		}
+	}
+	return newResult(c.Name(), StatusPass, "DefaultLimitNOFILE=%v (%v)", value, source)
+}
+
+func (c *SystemdNofileChecker) Install() error {
+	content := fmt.Sprintf("[Manager]\nDefaultLimitNOFILE=%v\n", recommendedSystemdNofile)
+	if err := c.host.WriteFile(systemdNofileDropIn, content); err != nil {
+		return err
+	}
+	logrus.Warnf("Wrote %v, run systemctl daemon-reload for the new services to take effect", systemdNofileDropIn)
+	return nil
+}
+
+// getDefaultLimitNofile returns the effective DefaultLimitNOFILE and the file
+// setting it. The drop-ins are applied in the order of their names after
+// system.conf, and the last assignment wins.
+func (c *SystemdNofileChecker) getDefaultLimitNofile() (string, string, error) {
+	value, source := systemdDefaultNofile, "systemd default"
+
+	exists, err := c.host.FileExists(systemdSystemConfPath)
+	if err != nil {
+		return "", "", err
+	}
+	if exists {
+		content, err := c.host.ReadFile(systemdSystemConfPath)
+		if err != nil {
+			return "", "", err
+		}
+		if v, ok := getLastDefaultLimitNofile(content); ok {
+			value, source = v, systemdSystemConfPath
+		}
+	}
+
+	dropIns, err := findConfigFiles(c.host, systemdSystemConfDirectories, ".conf")
+	if err != nil {
+		return "", "", err
+	}
+	// The drop-ins in /etc override the ones of the same names in the other directories
+	byName := map[string]string{}
+	for _, path := range dropIns {
+		byName[filepath.Base(path)] = path
+	}
+	names := []string{}
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content, err := c.host.ReadFile(byName[name])
+		if err != nil {
+			return "", "", err
+		}
+		if v, ok := getLastDefaultLimitNofile(content); ok {
+			value, source = v, byName[name]
+		}
+	}
+	return value, source, nil
+}
+
+// getLastDefaultLimitNofile returns the value of the last DefaultLimitNOFILE
+// assignment in the config
+func getLastDefaultLimitNofile(content string) (string, bool) {
+	matches := defaultLimitNofileRegexp.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+	return matches[len(matches)-1][1], true
+}
@@ -0,0 +1,69 @@
+package checker
+
+import (
+	"strings"
+)
+
+var storageUnitKeywords = []string{"iscsi", "multipath", "nfs", "rpc", "nvme", "longhorn", "modules-load", "lvm", "udev", "mount"}
+
+// SystemdStateChecker checks that systemd is not in the degraded state, which
+// means some units failed and the prerequisites may be partially broken
+type SystemdStateChecker struct {
+	host Host
+}
+
+func NewSystemdStateChecker(options *Options) *SystemdStateChecker {
+	return &SystemdStateChecker{
+		host: options.Host,
+	}
+}
+
+func (c *SystemdStateChecker) Name() string {
+	return "systemd-state"
+}
+
+func (c *SystemdStateChecker) Check() *CheckResult {
+	systemd, err := isSystemd(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to detect systemd: %v", err)
+	}
+	if !systemd {
+		return newResult(c.Name(), StatusSkip, "host is not running systemd")
+	}
+
+	state, err := systemctl(c.host, "is-system-running")
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the systemd state: %v", err)
+	}
+	if state != "degraded" {
+		return newResult(c.Name(), StatusPass, "systemd state is %v", state)
+	}
+
+	output, err := systemctl(c.host, "list-units", "--failed", "--plain", "--no-legend")
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "systemd state is degraded, failed to list failed units: %v", err)
+	}
+
+	failed := []string{}
+	storage := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		unit := fields[0]
+		failed = append(failed, unit)
+		for _, keyword := range storageUnitKeywords {
+			if strings.Contains(unit, keyword) {
+				storage = append(storage, unit)
+				break
+			}
+		}
+	}
+
+	if len(storage) == 0 {
+		return newResult(c.Name(), StatusWarn, "systemd state is degraded, failed units: %v (none relevant to storage)", strings.Join(failed, ", "))
+	}
+	return newResult(c.Name(), StatusWarn, "systemd state is degraded, failed units relevant to storage: %v (all failed units: %v)",
+		strings.Join(storage, ", "), strings.Join(failed, ", "))
+}
@@ -0,0 +1,82 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// talosExtensionDirs are where the Talos system extensions install their
+// binaries
+var talosExtensionDirs = []string{"/usr/local/sbin", "/usr/local/bin"}
+
+// talosExtension is a Talos system extension providing binaries used by
+// Longhorn
+type talosExtension struct {
+	name     string
+	binaries []string
+}
+
+// talosExtensions are the system extensions required by Longhorn, which
+// replace the packages installed on the other distros
+var talosExtensions = []talosExtension{
+	{name: "siderolabs/iscsi-tools", binaries: []string{"iscsiadm", "iscsid"}},
+	{name: "siderolabs/util-linux-tools", binaries: []string{"fstrim"}},
+}
+
+// TalosExtensionsChecker checks that the system extensions required by
+// Longhorn are installed on Talos Linux, which has no package manager
+type TalosExtensionsChecker struct {
+	host Host
+}
+
+func NewTalosExtensionsChecker(options *Options) *TalosExtensionsChecker {
+	return &TalosExtensionsChecker{
+		host: options.Host,
+	}
+}
+
+func (c *TalosExtensionsChecker) Name() string {
+	return "talos-extensions"
+}
+
+func (c *TalosExtensionsChecker) Check() *CheckResult {
+	missing := []string{}
+	for _, extension := range talosExtensions {
+		for _, binary := range extension.binaries {
+			found, err := c.findBinary(binary)
+			if err != nil {
+				return newResult(c.Name(), StatusWarn, "Failed to find %v of system extension %v: %v", binary, extension.name, err)
+			}
+			if !found {
+				missing = append(missing, fmt.Sprintf("%v (%v not found)", extension.name, binary))
+				break
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return newResult(c.Name(), StatusFail,
+			"missing system extensions %v, add them to the Talos image with the Image Factory or to .machine.install.extensions of the machine config, then upgrade the node",
+			strings.Join(missing, ", "))
+	}
+
+	names := []string{}
+	for _, extension := range talosExtensions {
+		names = append(names, extension.name)
+	}
+	return newResult(c.Name(), StatusPass, "system extensions %v installed", strings.Join(names, ", "))
+}
+
+// findBinary checks whether the binary is installed by a system extension
+func (c *TalosExtensionsChecker) findBinary(binary string) (bool, error) {
+	for _, dir := range talosExtensionDirs {
+		exists, err := c.host.FileExists(dir + "/" + binary)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,72 @@
+package checker
+
+import (
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+// tunedStorageProfiles are the stock tuned profiles suitable for the storage
+// nodes, besides the ones named after throughput, latency or performance
+var tunedStorageProfiles = map[string]bool{
+	"virtual-guest": true,
+	"virtual-host":  true,
+	"hpc-compute":   true,
+}
+
+// TunedChecker checks that the active tuned profile is tuned for throughput or
+// latency rather than for saving power, e.g. powersave or balanced
+type TunedChecker struct {
+	host Host
+}
+
+func NewTunedChecker(options *Options) *TunedChecker {
+	return &TunedChecker{
+		host: options.Host,
+	}
+}
+
+func (c *TunedChecker) Name() string {
+	return "tuned-profile"
+}
+
+func (c *TunedChecker) Check() *CheckResult {
+	if _, err := lookPath(c.host, "tuned-adm"); err != nil {
+		return newResult(c.Name(), StatusSkip, "tuned is not installed")
+	}
+
+	output, err := c.host.Execute("tuned-adm", []string{"active"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the active tuned profile: %v", err)
+	}
+
+	// e.g. "Current active profile: virtual-guest throughput-performance"
+	profile := ""
+	for _, line := range strings.Split(output, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "Current active profile:"); ok {
+			profile = strings.TrimSpace(value)
+		}
+	}
+	if profile == "" {
+		return newResult(c.Name(), StatusSkip, "No tuned profile is active")
+	}
+
+	for _, name := range strings.Fields(profile) {
+		if isTunedStorageProfile(name) {
+			return newResult(c.Name(), StatusPass, "Active tuned profile is %v", profile)
+		}
+	}
+	return newResult(c.Name(), StatusWarn, "Active tuned profile %v is not tuned for the storage, consider throughput-performance or latency-performance", profile)
+}
+
+func isTunedStorageProfile(name string) bool {
+	if tunedStorageProfiles[name] {
+		return true
+	}
+	for _, keyword := range []string{"throughput", "latency", "performance"} {
+		if strings.Contains(name, keyword) {
+			return true
+		}
+	}
+	return false
+}
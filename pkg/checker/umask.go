@@ -0,0 +1,46 @@
+package checker
+
+import (
+	"strconv"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+// umaskReadBits are the bits making the created files unreadable by the
+// group and others
+const umaskReadBits = 0044
+
+// UmaskChecker checks that the umask used by the installation does not make
+// the written config files, e.g. in modules-load.d and sysctl.d, unreadable
+// by the services
+type UmaskChecker struct {
+	host Host
+}
+
+func NewUmaskChecker(options *Options) *UmaskChecker {
+	return &UmaskChecker{
+		host: options.Host,
+	}
+}
+
+func (c *UmaskChecker) Name() string {
+	return "umask"
+}
+
+func (c *UmaskChecker) Check() *CheckResult {
+	output, err := c.host.Execute("sh", []string{"-c", "umask"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to get the umask: %v", err)
+	}
+
+	value := strings.TrimSpace(output)
+	umask, err := strconv.ParseUint(value, 8, 32)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to parse the umask %q: %v", value, err)
+	}
+	if umask&umaskReadBits != 0 {
+		return newResult(c.Name(), StatusWarn, "umask %04o is more restrictive than 0022, the installed config files may be unreadable by the services", umask)
+	}
+	return newResult(c.Name(), StatusPass, "umask %04o", umask)
+}
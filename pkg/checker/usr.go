@@ -0,0 +1,65 @@
+package checker
+
+import (
+	"strings"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+const (
+	usrPath = "/usr"
+
+	PreconditionUsrWritable = "usr-writable"
+)
+
+// UsrWritableChecker checks that /usr is writable before the installation
+// installs the packages there, which fails on the immutable distros mounting
+// /usr read-only. On openSUSE MicroOS and SLE Micro, zypper installs the
+// packages with transactional-update instead.
+type UsrWritableChecker struct {
+	host           Host
+	packageManager types.PackageManager
+}
+
+func NewUsrWritableChecker(options *Options) *UsrWritableChecker {
+	return &UsrWritableChecker{
+		host:           options.Host,
+		packageManager: options.PackageManager,
+	}
+}
+
+func (c *UsrWritableChecker) Name() string {
+	return PreconditionUsrWritable
+}
+
+func (c *UsrWritableChecker) Check() *CheckResult {
+	mounts, err := getMounts(c.host)
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to get mounts: %v", err)
+	}
+
+	m := findMount(mounts, usrPath)
+	if m == nil {
+		return newResult(c.Name(), StatusFail, "Failed to find the mount of %v", usrPath)
+	}
+	flags := strings.Join(m.Options, ",")
+
+	writable, err := isWritable(c.host, usrPath)
+	if err != nil {
+		return newResult(c.Name(), StatusFail, "Failed to check whether %v is writable: %v", usrPath, err)
+	}
+	if m.IsReadOnly() || !writable {
+		if c.packageManager == types.PackageManagerZypper {
+			if path, err := lookPath(c.host, "transactional-update"); err == nil && path != "" {
+				return newResult(c.Name(), StatusPass,
+					"%v is read-only (mount %v, flags: %v), the packages are installed with transactional-update into a new snapshot used after rebooting",
+					usrPath, m.MountPoint, flags)
+			}
+		}
+		return newResult(c.Name(), StatusFail,
+			"%v is read-only (mount %v, flags: %v), install the packages with the tools of the immutable distro, e.g. transactional-update or rpm-ostree, or in the image of the node",
+			usrPath, m.MountPoint, flags)
+	}
+
+	return newResult(c.Name(), StatusPass, "%v is writable (mount %v, flags: %v)", usrPath, m.MountPoint, flags)
+}
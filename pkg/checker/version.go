@@ -0,0 +1,37 @@
+package checker
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var versionRegexp = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// parseVersion returns the first dotted version number in the string
+func parseVersion(s string) string {
+	return versionRegexp.FindString(s)
+}
+
+// compareVersions compares two dotted version numbers, and returns -1, 0 or 1
+// when a is lower than, equal to or higher than b
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
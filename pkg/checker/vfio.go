@@ -0,0 +1,50 @@
+package checker
+
+import (
+	"os"
+)
+
+const (
+	driverVFIO = "vfio-pci"
+
+	vfioDevicePath = "/dev/vfio/vfio"
+)
+
+// VFIOChecker checks that /dev/vfio/vfio is accessible when the NVMe devices
+// are bound to vfio-pci for the v2 data engine
+type VFIOChecker struct {
+	host Host
+}
+
+func NewVFIOChecker(options *Options) *VFIOChecker {
+	return &VFIOChecker{
+		host: options.Host,
+	}
+}
+
+func (c *VFIOChecker) Name() string {
+	return "vfio"
+}
+
+func (c *VFIOChecker) Check() *CheckResult {
+	exists, err := c.host.FileExists(vfioDevicePath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "VFIO is unavailable: %v", err)
+	}
+	if !exists {
+		return newResult(c.Name(), StatusWarn, "VFIO is unavailable: %v does not exist", vfioDevicePath)
+	}
+
+	fn := func() (string, error) {
+		file, err := os.OpenFile(vfioDevicePath, os.O_RDWR, 0)
+		if err != nil {
+			return "", err
+		}
+		return "", file.Close()
+	}
+	if _, err := c.host.Probe("open:"+vfioDevicePath, fn); err != nil {
+		return newResult(c.Name(), StatusWarn, "VFIO is present but %v is inaccessible: %v", vfioDevicePath, err)
+	}
+
+	return newResult(c.Name(), StatusPass, "VFIO is available at %v", vfioDevicePath)
+}
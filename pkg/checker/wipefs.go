@@ -0,0 +1,17 @@
+package checker
+
+import (
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// NewWipefsChecker returns the checker of wipefs used to prepare the disks
+// for Longhorn
+func NewWipefsChecker(options *Options) *BinaryChecker {
+	return NewBinaryChecker("wipefs", options, []string{"wipefs"}, map[types.PackageManager]string{
+		types.PackageManagerApt:    "util-linux",
+		types.PackageManagerYum:    "util-linux",
+		types.PackageManagerZypper: "util-linux",
+		types.PackageManagerApk:    "wipefs",
+		types.PackageManagerPacman: "util-linux",
+	})
+}
@@ -0,0 +1,36 @@
+package checker
+
+import (
+	"strings"
+)
+
+const procVersionPath = "/proc/version"
+
+// WSLChecker detects Windows Subsystem for Linux, where many assumptions
+// about the host do not hold and several checks may be misleading
+type WSLChecker struct {
+	host Host
+}
+
+func NewWSLChecker(options *Options) *WSLChecker {
+	return &WSLChecker{
+		host: options.Host,
+	}
+}
+
+func (c *WSLChecker) Name() string {
+	return "wsl"
+}
+
+func (c *WSLChecker) Check() *CheckResult {
+	version, err := c.host.ReadFile(procVersionPath)
+	if err != nil {
+		return newResult(c.Name(), StatusWarn, "Failed to detect WSL: %v", err)
+	}
+
+	if strings.Contains(strings.ToLower(version), "microsoft") {
+		return newResult(c.Name(), StatusWarn,
+			"WSL detected (%v), the module and service checks may not reflect a real production host", strings.TrimSpace(version))
+	}
+	return newResult(c.Name(), StatusPass, "not running in WSL")
+}
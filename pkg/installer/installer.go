@@ -4,11 +4,12 @@ import (
 	"fmt"
 
 	lhns "github.com/longhorn/go-common-libs/namespace"
-	lhtypes "github.com/longhorn/go-common-libs/types"
 
 	"github.com/longhorn/longhorn-preflight/pkg/installer/apt"
 	"github.com/longhorn/longhorn-preflight/pkg/installer/command"
+	"github.com/longhorn/longhorn-preflight/pkg/installer/zypper"
 	"github.com/longhorn/longhorn-preflight/pkg/types"
+	"github.com/longhorn/longhorn-preflight/pkg/utils"
 )
 
 type Installer struct {
@@ -21,16 +22,24 @@ type Installer struct {
 }
 
 func NewInstaller(packageManager types.PackageManager) (*Installer, error) {
-	namespaces := []lhtypes.Namespace{
-		lhtypes.NamespaceMnt,
-		lhtypes.NamespaceNet,
+	executor, err := utils.NewHostExecutor()
+	if err != nil {
+		return nil, err
 	}
 
-	executor, err := lhns.NewNamespaceExecutor(lhtypes.ProcessSelf, lhtypes.HostProcDirectory, namespaces)
+	return newInstaller(packageManager, executor)
+}
+
+// GetModules returns the kernel modules required by Longhorn
+func GetModules(packageManager types.PackageManager) ([]string, error) {
+	i, err := newInstaller(packageManager, nil)
 	if err != nil {
 		return nil, err
 	}
+	return i.modules, nil
+}
 
+func newInstaller(packageManager types.PackageManager, executor *lhns.Executor) (*Installer, error) {
 	switch packageManager {
 	case types.PackageManagerApt:
 		return &Installer{
@@ -62,7 +71,7 @@ func NewInstaller(packageManager types.PackageManager) (*Installer, error) {
 	case types.PackageManagerZypper:
 		return &Installer{
 			name:    types.PackageManagerZypper,
-			command: nil,
+			command: zypper.NewCommand(executor),
 			packages: []string{
 				"nfs-client", "open-iscsi", "nvme-cli",
 			},
@@ -87,6 +96,16 @@ func NewInstaller(packageManager types.PackageManager) (*Installer, error) {
 			pythonPackages: []string{},
 			modules:        []string{},
 		}, nil
+	case types.PackageManagerTalos:
+		// The packages are replaced by the system extensions, and the modules
+		// are loaded by the machine config
+		return &Installer{
+			name:           types.PackageManagerTalos,
+			command:        nil,
+			packages:       []string{},
+			pythonPackages: []string{},
+			modules:        []string{},
+		}, nil
 	default:
 		return nil, fmt.Errorf("unknown package manager %s", packageManager)
 	}
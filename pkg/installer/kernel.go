@@ -0,0 +1,62 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	lhtypes "github.com/longhorn/go-common-libs/types"
+
+	"github.com/longhorn/longhorn-preflight/pkg/types"
+)
+
+// getKernelPackage returns the name of the kernel package of the version
+func (i *Installer) getKernelPackage(version string) (string, error) {
+	switch i.name {
+	case types.PackageManagerApt:
+		return "linux-image-" + version, nil
+	case types.PackageManagerZypper:
+		return "kernel-default-" + version, nil
+	default:
+		return "", fmt.Errorf("installing a kernel with %v is not supported", i.name)
+	}
+}
+
+// isPackageInstalled checks whether the package is installed, since apt and
+// zypper also succeed installing a package already installed
+func (i *Installer) isPackageInstalled(pkg string) bool {
+	switch i.name {
+	case types.PackageManagerApt:
+		output, err := i.command.Execute("dpkg-query", []string{"-W", "-f=${Status}", pkg}, lhtypes.ExecuteDefaultTimeout)
+		return err == nil && strings.Contains(output, "install ok installed")
+	default:
+		_, err := i.command.Execute("rpm", []string{"-q", pkg}, lhtypes.ExecuteDefaultTimeout)
+		return err == nil
+	}
+}
+
+// InstallKernel installs the kernel package of the version unless the node
+// already runs it or has it installed. It returns the name of the package and
+// whether it was newly installed, in which case the node boots into the new
+// kernel only after a reboot.
+func (i *Installer) InstallKernel(version string) (string, bool, error) {
+	pkg, err := i.getKernelPackage(version)
+	if err != nil {
+		return "", false, err
+	}
+
+	release, err := i.command.Execute("uname", []string{"-r"}, lhtypes.ExecuteDefaultTimeout)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get the running kernel: %v", err)
+	}
+	if strings.TrimSpace(release) == version {
+		return pkg, false, nil
+	}
+	if i.isPackageInstalled(pkg) {
+		return pkg, false, nil
+	}
+
+	if _, err := i.InstallPackage(pkg); err != nil {
+		return "", false, err
+	}
+	return pkg, true, nil
+}
@@ -1,10 +1,23 @@
 package installer
 
-import "github.com/sirupsen/logrus"
+import (
+	"fmt"
 
-// InstallPythonPackages installs Python packages with pip
-func (i *Installer) ProbeModules() {
+	"github.com/sirupsen/logrus"
+)
+
+// ProbeModules loads the kernel modules except the excluded ones, which are
+// not used by the targeted Longhorn release
+func (i *Installer) ProbeModules(excluded []string) {
+	skipped := map[string]bool{}
+	for _, mod := range excluded {
+		skipped[mod] = true
+	}
 	for _, mod := range i.modules {
+		if skipped[mod] {
+			logrus.Infof("Skipped probing module %s since it is not used by the Longhorn release", mod)
+			continue
+		}
 		logrus.Infof("Probing module %s", mod)
 
 		_, err := i.command.Modprobe(mod)
@@ -37,9 +50,33 @@ func (i *Installer) UpdatePackageList() (string, error) {
 
 // InstallPackage install a package with a package manager
 func (i *Installer) InstallPackage(name string) (string, error) {
+	if i.command == nil {
+		return "", fmt.Errorf("installing packages with %v is not supported", i.name)
+	}
 	return i.command.InstallPackage(name)
 }
 
+// transactionalCommand is a command changing the packages in a snapshot the
+// node boots into after rebooting
+type transactionalCommand interface {
+	IsTransactional() bool
+	IsRebootRequired() bool
+}
+
+// IsTransactional checks whether the packages are installed in a snapshot, so
+// they can be installed even though /usr is read-only
+func (i *Installer) IsTransactional() bool {
+	t, ok := i.command.(transactionalCommand)
+	return ok && t.IsTransactional()
+}
+
+// IsRebootRequired checks whether the packages were installed in a snapshot
+// the node has to be rebooted into
+func (i *Installer) IsRebootRequired() bool {
+	t, ok := i.command.(transactionalCommand)
+	return ok && t.IsRebootRequired()
+}
+
 // UninstallPackage uninstall a package with a package manager
 func (i *Installer) UninstallPackage(name string) (string, error) {
 	return i.command.UninstallPackage(name)
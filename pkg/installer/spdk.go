@@ -1,6 +1,7 @@
 package installer
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,9 +15,27 @@ import (
 const (
 	spdkPath       = "/host/tmp/longhorn-spdk"
 	spdkPathOnHost = "/tmp/longhorn-spdk"
+
+	driverUIOPCIGeneric = "uio_pci_generic"
+	driverVFIOPCI       = "vfio-pci"
 )
 
-func (i *Installer) InstallSPDKDeps() error {
+// GetSPDKModules returns the kernel modules required by the v2 data engine
+// binding the NVMe devices with the driver. Without an override, SPDK falls
+// back to uio_pci_generic when vfio-pci is not usable, so the uio modules are
+// loaded unless the driver is vfio-pci.
+func GetSPDKModules(driverOverride string) []string {
+	modules := []string{"nvme-tcp"}
+	if driverOverride != driverVFIOPCI {
+		modules = append(modules, "uio", driverUIOPCIGeneric)
+	}
+	return modules
+}
+
+// InstallSPDKDeps installs the SPDK dependencies, loads the modules and
+// configures the SPDK environment with the HugePages of 2MiB, binding the
+// NVMe devices with the driver
+func (i *Installer) InstallSPDKDeps(hugePages int, driverOverride string) error {
 	// Blindly remove the SPDK source code directory if it exists
 	if err := os.RemoveAll(spdkPath); err != nil {
 		return err
@@ -34,10 +53,17 @@ func (i *Installer) InstallSPDKDeps() error {
 		logrus.Infof("Successfully installed SPDK dependencies")
 	}
 
+	for _, module := range GetSPDKModules(driverOverride) {
+		logrus.Infof("Probing module %s", module)
+		if _, err := i.command.Modprobe(module); err != nil {
+			logrus.WithError(err).Errorf("Failed to probe module %s", module)
+		}
+	}
+
 	// Configure SPDK environment
-	logrus.Infof("Configuring SPDK environment")
-	args := getArgsForConfiguringSPDKEnv()
-	if _, err := i.command.Execute("bash", args, lhtypes.ExecuteNoTimeout); err != nil {
+	logrus.Infof("Configuring SPDK environment with %d HugePages of 2MiB and driver %v", hugePages, driverOverride)
+	args := getArgsForConfiguringSPDKEnv(hugePages, driverOverride)
+	if _, err := i.command.Execute("env", args, lhtypes.ExecuteNoTimeout); err != nil {
 		logrus.WithError(err).Errorf("Failed to configure SPDK environment")
 	} else {
 		logrus.Infof("Successfully configured SPDK environment")
@@ -46,8 +72,14 @@ func (i *Installer) InstallSPDKDeps() error {
 	return nil
 }
 
-func getArgsForConfiguringSPDKEnv() []string {
-	args := []string{filepath.Join(spdkPathOnHost, "scripts/setup.sh")}
+// getArgsForConfiguringSPDKEnv returns the arguments of env running setup.sh,
+// which takes the HugePages in MiB and the driver from the environment
+func getArgsForConfiguringSPDKEnv(hugePages int, driverOverride string) []string {
+	args := []string{
+		fmt.Sprintf("HUGEMEM=%d", hugePages*2),
+		"DRIVER_OVERRIDE=" + driverOverride,
+		"bash", filepath.Join(spdkPathOnHost, "scripts/setup.sh"),
+	}
 	value := os.Getenv("SPDK_OPTIONS")
 	if value != "" {
 		logrus.Infof("Configuring SPDK environment with custom options: %v", os.Getenv("SPDK_OPTIONS"))
@@ -0,0 +1,109 @@
+package zypper
+
+import (
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	lhns "github.com/longhorn/go-common-libs/namespace"
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+type Command struct {
+	executor *lhns.Executor
+
+	// transactional is whether the packages are installed with
+	// transactional-update since the root filesystem is read-only, e.g. on
+	// openSUSE MicroOS and SLE Micro. It is detected on the first use.
+	transactional *bool
+	// snapshotChanged is whether the packages were changed in a new snapshot,
+	// which requires rebooting the node
+	snapshotChanged bool
+}
+
+func NewCommand(executor *lhns.Executor) *Command {
+	return &Command{
+		executor: executor,
+	}
+}
+
+// IsTransactional checks whether transactional-update is available and the
+// root filesystem is read-only
+func (c *Command) IsTransactional() bool {
+	if c.transactional != nil {
+		return *c.transactional
+	}
+
+	transactional := false
+	if _, err := c.executor.Execute("transactional-update", []string{"--version"}, lhtypes.ExecuteDefaultTimeout); err == nil {
+		output, err := c.executor.Execute("findmnt", []string{"-n", "-o", "OPTIONS", "/"}, lhtypes.ExecuteDefaultTimeout)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to get the mount options of the root filesystem")
+		}
+		for _, option := range strings.Split(strings.TrimSpace(output), ",") {
+			if option == "ro" {
+				transactional = true
+			}
+		}
+	}
+	c.transactional = &transactional
+	return transactional
+}
+
+// transactionalUpdate runs the zypper command in the snapshot prepared by
+// transactional-update. The changes of the consecutive commands are
+// accumulated in the same snapshot, which is used after rebooting the node.
+func (c *Command) transactionalUpdate(args ...string) (string, error) {
+	output, err := c.executor.Execute("transactional-update", append([]string{"--continue", "--non-interactive"}, args...), lhtypes.ExecuteNoTimeout)
+	if err == nil {
+		c.snapshotChanged = true
+	}
+	return output, err
+}
+
+// IsRebootRequired checks whether the packages were changed in a new snapshot
+// the node has to be rebooted into
+func (c *Command) IsRebootRequired() bool {
+	return c.snapshotChanged
+}
+
+// UpdatePackageList updates list of available packages
+func (c *Command) UpdatePackageList() (string, error) {
+	return c.executor.Execute("zypper", []string{"--non-interactive", "refresh"}, lhtypes.ExecuteNoTimeout)
+}
+
+// InstallPackage executes the installation command
+func (c *Command) InstallPackage(name string) (string, error) {
+	if c.IsTransactional() {
+		return c.transactionalUpdate("pkg", "install", name)
+	}
+	return c.executor.Execute("zypper", []string{"--non-interactive", "install", name}, lhtypes.ExecuteNoTimeout)
+}
+
+// UninstallPackage executes the uninstallation command
+func (c *Command) UninstallPackage(name string) (string, error) {
+	if c.IsTransactional() {
+		return c.transactionalUpdate("pkg", "remove", name)
+	}
+	return c.executor.Execute("zypper", []string{"--non-interactive", "remove", name}, lhtypes.ExecuteNoTimeout)
+}
+
+// ListPackages lists all installed packages
+func (c *Command) ListPackages() (string, error) {
+	return c.executor.Execute("rpm", []string{"-qa"}, lhtypes.ExecuteNoTimeout)
+}
+
+// PipInstallPackage executes the pip installation command
+func (c *Command) PipInstallPackage(name string) (string, error) {
+	return c.executor.Execute("pip3", []string{"install", name}, lhtypes.ExecuteNoTimeout)
+}
+
+// Execute executes the given command with the specified environment variables, binary, and arguments.
+func (c *Command) Execute(binary string, args []string, timeout time.Duration) (string, error) {
+	return c.executor.Execute(binary, args, timeout)
+}
+
+func (c *Command) Modprobe(module string) (string, error) {
+	return c.executor.Execute("modprobe", []string{module}, lhtypes.ExecuteNoTimeout)
+}
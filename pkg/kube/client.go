@@ -0,0 +1,139 @@
+package kube
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	serviceAccountDirectory = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Client is a minimal client of the Kubernetes API using the service account
+// of the pod
+type Client struct {
+	server string
+	token  string
+	client *http.Client
+}
+
+// APIError is the error responded by the Kubernetes API
+type APIError struct {
+	Method     string
+	Path       string
+	Status     string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v %v responded %v: %v", e.Method, e.Path, e.Status, e.Body)
+}
+
+// IsNotFound checks whether the error is the API responding the resource is
+// not found
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// GetNamespace returns the namespace of the pod from the service account
+func GetNamespace() (string, error) {
+	namespace, err := os.ReadFile(filepath.Join(serviceAccountDirectory, "namespace"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the service account namespace: %v", err)
+	}
+	return strings.TrimSpace(string(namespace)), nil
+}
+
+// NewInClusterClient returns the client authenticated with the service
+// account token mounted in the pod
+func NewInClusterClient() (*Client, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in-cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+
+	token, err := os.ReadFile(filepath.Join(serviceAccountDirectory, "token"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the service account token: %v", err)
+	}
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDirectory, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the service account CA: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("failed to parse the service account CA")
+	}
+
+	return &Client{
+		server: "https://" + net.JoinHostPort(host, port),
+		token:  strings.TrimSpace(string(token)),
+		client: &http.Client{
+			Timeout: defaultTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		},
+	}, nil
+}
+
+// do sends the request with the JSON body, and decodes the JSON response into
+// out if not nil
+func (c *Client) do(method, path, contentType string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.server+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{
+			Method:     method,
+			Path:       path,
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(data)),
+		}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
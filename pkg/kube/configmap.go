@@ -0,0 +1,61 @@
+package kube
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ConfigMap is the subset of a ConfigMap used by the tool
+type ConfigMap struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   ObjectMeta        `json:"metadata"`
+	Data       map[string]string `json:"data"`
+}
+
+// ObjectMeta is the subset of the metadata of an object used by the tool
+type ObjectMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func getConfigMapPath(namespace, name string) string {
+	return fmt.Sprintf("/api/v1/namespaces/%v/configmaps/%v", url.PathEscape(namespace), url.PathEscape(name))
+}
+
+// GetConfigMap returns the ConfigMap
+func (c *Client) GetConfigMap(namespace, name string) (*ConfigMap, error) {
+	configMap := &ConfigMap{}
+	if err := c.do("GET", getConfigMapPath(namespace, name), "", nil, configMap); err != nil {
+		return nil, err
+	}
+	return configMap, nil
+}
+
+// PatchConfigMapData sets the keys of the ConfigMap data, leaving the other
+// keys as they are, so the pods on different nodes update their own keys
+// without conflicts. The ConfigMap is created if it does not exist.
+func (c *Client) PatchConfigMapData(namespace, name string, data map[string]string) error {
+	patch := map[string]interface{}{
+		"data": data,
+	}
+	err := c.do("PATCH", getConfigMapPath(namespace, name), "application/merge-patch+json", patch, nil)
+	if !IsNotFound(err) {
+		return err
+	}
+
+	configMap := &ConfigMap{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Metadata:   ObjectMeta{Name: name, Namespace: namespace},
+		Data:       data,
+	}
+	path := fmt.Sprintf("/api/v1/namespaces/%v/configmaps", url.PathEscape(namespace))
+	err = c.do("POST", path, "application/json", configMap, nil)
+	if apiErr, ok := err.(*APIError); ok && apiErr.StatusCode == http.StatusConflict {
+		// Created by the pod on another node meanwhile
+		return c.do("PATCH", getConfigMapPath(namespace, name), "application/merge-patch+json", patch, nil)
+	}
+	return err
+}
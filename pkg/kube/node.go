@@ -0,0 +1,137 @@
+package kube
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ResourceAttributes identifies the access checked by a SelfSubjectAccessReview
+type ResourceAttributes struct {
+	Verb        string `json:"verb"`
+	Namespace   string `json:"namespace,omitempty"`
+	Group       string `json:"group"`
+	Resource    string `json:"resource"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+func (a *ResourceAttributes) String() string {
+	resource := a.Resource
+	if a.Subresource != "" {
+		resource += "/" + a.Subresource
+	}
+	if a.Group != "" {
+		resource += "." + a.Group
+	}
+	if a.Name != "" {
+		resource += " " + a.Name
+	}
+	if a.Namespace != "" {
+		resource += " in namespace " + a.Namespace
+	}
+	return a.Verb + " " + resource
+}
+
+type selfSubjectAccessReview struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		ResourceAttributes *ResourceAttributes `json:"resourceAttributes"`
+	} `json:"spec"`
+	Status struct {
+		Allowed bool   `json:"allowed"`
+		Reason  string `json:"reason"`
+	} `json:"status"`
+}
+
+// CanI checks whether the service account is allowed the access with a
+// SelfSubjectAccessReview, and returns the reason given by the authorizer
+func (c *Client) CanI(attributes *ResourceAttributes) (bool, string, error) {
+	review := &selfSubjectAccessReview{
+		APIVersion: "authorization.k8s.io/v1",
+		Kind:       "SelfSubjectAccessReview",
+	}
+	review.Spec.ResourceAttributes = attributes
+
+	if err := c.do("POST", "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", "application/json", review, review); err != nil {
+		return false, "", err
+	}
+	return review.Status.Allowed, review.Status.Reason, nil
+}
+
+// PatchNodeAnnotations sets the annotations of the node
+func (c *Client) PatchNodeAnnotations(node string, annotations map[string]string) error {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	}
+	path := fmt.Sprintf("/api/v1/nodes/%v", url.PathEscape(node))
+	return c.do("PATCH", path, "application/merge-patch+json", patch, nil)
+}
+
+// NodeCondition is a condition in the status of a node
+type NodeCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastHeartbeatTime  time.Time `json:"lastHeartbeatTime,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime,omitempty"`
+}
+
+type nodeStatus struct {
+	Status struct {
+		Conditions []*NodeCondition `json:"conditions"`
+	} `json:"status"`
+}
+
+// SetNodeCondition sets the condition in the status of the node, which keeps
+// the last transition time of the existing condition of the type unless its
+// status changes
+func (c *Client) SetNodeCondition(node string, condition *NodeCondition) error {
+	path := fmt.Sprintf("/api/v1/nodes/%v/status", url.PathEscape(node))
+
+	current := &nodeStatus{}
+	if err := c.do("GET", path, "", nil, current); err != nil {
+		return err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	condition.LastHeartbeatTime = now
+	condition.LastTransitionTime = now
+	for _, existing := range current.Status.Conditions {
+		if existing.Type == condition.Type && existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+	}
+
+	// The strategic merge patch merges the conditions by their types
+	patch := &nodeStatus{}
+	patch.Status.Conditions = []*NodeCondition{condition}
+	return c.do("PATCH", path, "application/strategic-merge-patch+json", patch, nil)
+}
+
+type nodeList struct {
+	Items []struct {
+		Metadata ObjectMeta `json:"metadata"`
+	} `json:"items"`
+}
+
+// ListNodeNames returns the names of the nodes matching the label selector,
+// or of all the nodes if the selector is empty
+func (c *Client) ListNodeNames(selector string) ([]string, error) {
+	path := "/api/v1/nodes"
+	if selector != "" {
+		path += "?labelSelector=" + url.QueryEscape(selector)
+	}
+	list := &nodeList{}
+	if err := c.do("GET", path, "", nil, list); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, item := range list.Items {
+		names = append(names, item.Metadata.Name)
+	}
+	return names, nil
+}
@@ -9,4 +9,10 @@ const (
 	PackageManagerZypper  = PackageManager("zypper")
 	PackageManagerApk     = PackageManager("apk")
 	PackageManagerPacman  = PackageManager("pacman")
+	// PackageManagerTalos is used on Talos Linux, which has no package manager
+	// and installs the software as system extensions instead
+	PackageManagerTalos = PackageManager("talos")
 )
+
+// HostRootDirectory is where the host root filesystem is mounted in the container
+const HostRootDirectory = "/host"
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	lhns "github.com/longhorn/go-common-libs/namespace"
+	lhtypes "github.com/longhorn/go-common-libs/types"
+)
+
+// NewHostExecutor returns an executor running commands in the host mount and
+// network namespaces
+func NewHostExecutor() (*lhns.Executor, error) {
+	namespaces := []lhtypes.Namespace{
+		lhtypes.NamespaceMnt,
+		lhtypes.NamespaceNet,
+	}
+
+	return lhns.NewNamespaceExecutor(lhtypes.ProcessSelf, lhtypes.HostProcDirectory, namespaces)
+}
@@ -6,18 +6,21 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
 
 	"github.com/longhorn/longhorn-preflight/pkg/types"
 )
 
 func GetPackageManager(platform string) (types.PackageManager, error) {
 	switch platform {
-	case "sles", "suse", "opensuse", "opensuse-leap":
+	case "sles", "suse", "opensuse", "opensuse-leap", "opensuse-tumbleweed", "opensuse-microos", "sle-micro", "sl-micro":
 		return types.PackageManagerZypper, nil
 	case "ubuntu", "debian":
 		return types.PackageManagerApt, nil
 	case "rhel", "ol":
 		return types.PackageManagerYum, nil
+	case "talos":
+		return types.PackageManagerTalos, nil
 	default:
 		return types.PackageManagerUnknown, fmt.Errorf("unknown platform %s", platform)
 	}
@@ -42,6 +45,11 @@ func GetOSRelease() (string, error) {
 	return parseOSreleaseFile(lines)
 }
 
+// ParseOSRelease returns the platform ID in the content of an os-release file
+func ParseOSRelease(content string) (string, error) {
+	return parseOSreleaseFile(strings.Split(content, "\n"))
+}
+
 func parseOSreleaseFile(lines []string) (string, error) {
 	var platform string
 
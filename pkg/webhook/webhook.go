@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/longhorn/longhorn-preflight/pkg/checker"
+)
+
+const (
+	EventRunStarted  = "run-started"
+	EventRunFinished = "run-finished"
+	EventResults     = "results"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// Event is the payload posted to the webhook
+type Event struct {
+	Type       string                 `json:"type"`
+	Node       string                 `json:"node"`
+	ConfigHash string                 `json:"configHash"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Summary    *checker.Summary       `json:"summary,omitempty"`
+	Results    []*checker.CheckResult `json:"results,omitempty"`
+	// Error is why the run aborted, in the run-finished event
+	Error string `json:"error,omitempty"`
+}
+
+// Sink posts the events of a run to the webhook URL
+type Sink struct {
+	url        string
+	node       string
+	configHash string
+	client     *http.Client
+}
+
+func NewSink(url, node, configHash string) *Sink {
+	return &Sink{
+		url:        url,
+		node:       node,
+		configHash: configHash,
+		client:     &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+func (s *Sink) newEvent(eventType string) *Event {
+	return &Event{
+		Type:       eventType,
+		Node:       s.node,
+		ConfigHash: s.configHash,
+		Timestamp:  time.Now().UTC(),
+	}
+}
+
+// PostRunStarted posts the event when the run starts
+func (s *Sink) PostRunStarted() error {
+	return s.post(s.newEvent(EventRunStarted))
+}
+
+// PostRunFinished posts the summary when the run finishes, and the error if
+// the run aborted. The summary is nil if the run aborted before the checks
+// completed.
+func (s *Sink) PostRunFinished(summary *checker.Summary, runErr error) error {
+	event := s.newEvent(EventRunFinished)
+	event.Summary = summary
+	if runErr != nil {
+		event.Error = runErr.Error()
+	}
+	return s.post(event)
+}
+
+// PostResults posts the check results and the summary
+func (s *Sink) PostResults(results []*checker.CheckResult, summary *checker.Summary) error {
+	event := s.newEvent(EventResults)
+	event.Results = results
+	event.Summary = summary
+	return s.post(event)
+}
+
+func (s *Sink) post(event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %v responded %v to event %v", s.url, resp.Status, event.Type)
+	}
+	return nil
+}